@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Backend executes a command somewhere else than in a local process, e.g.
+// over ssh or in a container. Backends live in their own packages, or
+// behind build tags, and register themselves with RegisterBackend in an
+// init function, so that this package stays free of their dependencies:
+//
+//	import _ "example.com/command/ssh"
+//
+//	cmd, err := command.NewCommand(ctx, "uptime", command.WithBackend("ssh"))
+type Backend interface {
+	StdinPipe() (io.WriteCloser, error)
+	StdoutPipe() (io.ReadCloser, error)
+	StderrPipe() (io.ReadCloser, error)
+	Start() error
+	Wait() error
+
+	// ExitCode returns the exit code of the command once Wait returned.
+	ExitCode() int
+}
+
+// BackendFactory returns the Backend which executes name with args.
+type BackendFactory func(ctx context.Context, name string, args []string) (Backend, error)
+
+var backends = struct {
+	sync.RWMutex
+	factories map[string]BackendFactory
+}{factories: map[string]BackendFactory{}}
+
+// RegisterBackend makes a backend available to WithBackend by name. It
+// panics if name is empty, factory is nil or name is already registered.
+func RegisterBackend(name string, factory BackendFactory) {
+	backends.Lock()
+	defer backends.Unlock()
+	if name == "" || factory == nil {
+		panic("command: invalid backend registration")
+	}
+	if _, ok := backends.factories[name]; ok {
+		panic(fmt.Sprintf("command: backend %q registered twice", name))
+	}
+	backends.factories[name] = factory
+}
+
+// Backends returns the sorted names of the registered backends.
+func Backends() []string {
+	backends.RLock()
+	defer backends.RUnlock()
+	names := make([]string, 0, len(backends.factories))
+	for name := range backends.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithBackend executes the command with the registered backend name instead
+// of a local process. Options which configure the local process, like
+// WithDir or WithEnv, have no effect.
+func WithBackend(name string) Option {
+
+	return func(c *Command) error {
+		c.record("WithBackend", name)
+		backends.RLock()
+		factory, ok := backends.factories[name]
+		backends.RUnlock()
+		if !ok {
+			return fmt.Errorf("unknown backend %q", name)
+		}
+		backend, err := factory(c.ctx, c.name, c.args)
+		if err != nil {
+			return err
+		}
+		c.cmd = backend
+		return nil
+	}
+}
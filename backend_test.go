@@ -0,0 +1,72 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type backendMock struct {
+	CommandServiceMock
+	exit int
+}
+
+func (b *backendMock) ExitCode() int { return b.exit }
+
+func init() {
+	RegisterBackend("mock", func(ctx context.Context, name string, args []string) (Backend, error) {
+		if name == "fail" {
+			return nil, errors.New("errBackend")
+		}
+		b := &backendMock{CommandServiceMock: CommandServiceMock{stdout: name + " " + args[0]}}
+		if args[0] == "exit" {
+			b.errWait = true
+			b.exit = 3
+		}
+		return b, nil
+	})
+}
+
+func TestCommandBackend(t *testing.T) {
+	testCases := []struct {
+		name    string
+		command string
+		arg     string
+		backend string
+		stdout  []string
+		exit    int
+		err     error
+	}{
+		{name: "mock", command: "echo", arg: "1", backend: "mock", stdout: []string{"echo 1"}},
+		{name: "exit", command: "echo", arg: "exit", backend: "mock", stdout: []string{"echo exit"}, exit: 3},
+		{name: "factoryError", command: "fail", arg: "1", backend: "mock", err: errors.New("errBackend")},
+		{name: "unknown", command: "echo", arg: "1", backend: "ssh", err: errors.New(`unknown backend "ssh"`)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), tc.command, tc.arg, WithBackend(tc.backend))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			for event := range events {
+				validateResult(tt, tc.stdout, event.Data().Stdout())
+			}
+			validateResult(tt, tc.exit, (<-cmd.Wait()).ExitCode())
+		})
+	}
+}
+
+func TestRegisterBackend(t *testing.T) {
+	validateResult(t, true, func() (panicked bool) {
+		defer func() { panicked = recover() != nil }()
+		RegisterBackend("mock", func(ctx context.Context, name string, args []string) (Backend, error) { return nil, nil })
+		return false
+	}())
+	validateResult(t, []string{"mock"}, Backends())
+}
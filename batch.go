@@ -0,0 +1,107 @@
+package command
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithBatching groups consecutive lines of the same stream into a single
+// event in streaming mode, which reduces the channel overhead for commands
+// with a lot of output. A batch is emitted once it holds maxLines lines,
+// flushEvery after its first line unless flushEvery is zero, before a line
+// of the other stream and at the end of the output. A batch carries the
+// time of its first line. Events carrying an error, partial lines, progress
+// lines and chunks are not batched.
+func WithBatching(maxLines int, flushEvery time.Duration) Option {
+
+	return func(c *Command) error {
+		c.record("WithBatching", maxLines, flushEvery)
+		if maxLines < 1 {
+			return fmt.Errorf("invalid batch size: %d", maxLines)
+		}
+		if flushEvery < 0 {
+			return fmt.Errorf("invalid flush interval: %v", flushEvery)
+		}
+		c.batchLines = maxLines
+		c.batchInterval = flushEvery
+		return nil
+	}
+}
+
+// batchEvents returns the events of in with lines grouped into batches.
+func (c *Command) batchEvents(in <-chan Event) <-chan Event {
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+		var lines []string
+		var first time.Time
+		var isStderr bool
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		send := func(event Event) bool {
+			select {
+			case <-c.ctx.Done():
+				return false
+			case out <- event:
+				return true
+			}
+		}
+		flush := func() bool {
+			if len(lines) == 0 {
+				return true
+			}
+			data := newCommandResult(lines, []string{})
+			if isStderr {
+				data = newCommandResult([]string{}, lines)
+			}
+			lines = nil
+			if timer != nil {
+				timer.Stop()
+				timeout = nil
+			}
+			event := c.newEvent(data, nil)
+			event.time = first
+			return send(event)
+		}
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				d := v.Data()
+				if v.Error() != nil || v.Kind() == EventMatch || v.Kind() == EventProgress || d.Partial() || d.Progress() || d.Bytes() != nil {
+					if !flush() || !send(v) {
+						return
+					}
+					continue
+				}
+				stderr := len(d.Stderr()) > 0
+				if stderr != isStderr && !flush() {
+					return
+				}
+				isStderr = stderr
+				if len(lines) == 0 {
+					first = v.Time()
+				}
+				lines = append(lines, d.Out()...)
+				if len(lines) >= c.batchLines {
+					if !flush() {
+						return
+					}
+				} else if len(lines) == 1 && c.batchInterval > 0 {
+					timer = time.NewTimer(c.batchInterval)
+					timeout = timer.C
+				}
+			case <-timeout:
+				timeout = nil
+				if !flush() {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,72 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatching(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		maxLines int
+		interval time.Duration
+		stdout   []int
+		stderr   []int
+		err      error
+	}{
+		{name: "count", input: strings.Repeat("o", 25), maxLines: 10, stdout: []int{10, 10, 5}, stderr: []int{0, 0, 0}},
+		{name: "interval", input: "o.o", maxLines: 10, interval: time.Millisecond, stdout: []int{1, 1}, stderr: []int{0, 0}},
+		{name: "streams", input: "oooeeoo", maxLines: 10, stdout: []int{3, 0, 2}, stderr: []int{0, 2, 0}},
+		{name: "invalidSize", maxLines: 0, err: errors.New("invalid batch size: 0")},
+		{name: "invalidInterval", maxLines: 1, interval: -time.Second, err: errors.New("invalid flush interval: -1s")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", WithStreaming(), WithBatching(tc.maxLines, tc.interval))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			// o is a stdout line, e a stderr line and . waits for a batch
+			// to be flushed by the timer
+			in := make(chan Event)
+			events := cmd.batchEvents(in)
+			stdout := []int{}
+			stderr := []int{}
+			receive := func(event Event) {
+				validateError(tt, nil, event.Error())
+				stdout = append(stdout, len(event.Data().Stdout()))
+				stderr = append(stderr, len(event.Data().Stderr()))
+			}
+			for _, c := range tc.input {
+				if c == '.' {
+					receive(<-events)
+					continue
+				}
+				event := cmd.newEvent(newStreamData("line", c == 'e'), nil)
+			SendLoop:
+				for {
+					select {
+					case in <- event:
+						break SendLoop
+					case batch := <-events:
+						receive(batch)
+					}
+				}
+			}
+			close(in)
+			for event := range events {
+				receive(event)
+			}
+			validateResult(tt, tc.stdout, stdout)
+			validateResult(tt, tc.stderr, stderr)
+		})
+	}
+}
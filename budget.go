@@ -0,0 +1,87 @@
+package command
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ReasonBudget is the cancellation reason of commands which were killed
+// because the budget of their context ran out.
+const ReasonBudget = "budget exceeded"
+
+// ErrBudgetExceeded is returned by Execute if the budget attached to the
+// command's context by WithBudget is exhausted.
+var ErrBudgetExceeded = newCodedError("BUDGET_EXCEEDED", "command budget exceeded")
+
+type budgetKey struct{}
+
+// budget tracks the subprocess work done on behalf of a context.
+type budget struct {
+	mu          sync.Mutex
+	maxCommands int
+	maxDuration time.Duration
+	commands    int
+	used        time.Duration
+}
+
+// WithBudget returns a context which limits the commands executed with it
+// (or any context derived from it) to maxCommands executions and a total
+// run time of maxTotalDuration. A zero value disables the respective limit.
+//
+// Once the budget is exhausted Execute fails with ErrBudgetExceeded. Each
+// command is given at most the run time remaining when it starts and is
+// killed with the cancellation reason ReasonBudget if it runs longer, so
+// concurrent commands may together exceed maxTotalDuration.
+func WithBudget(ctx context.Context, maxCommands int, maxTotalDuration time.Duration) context.Context {
+	return context.WithValue(ctx, budgetKey{}, &budget{maxCommands: maxCommands, maxDuration: maxTotalDuration})
+}
+
+// acquire accounts for a new command and returns the run time it may use,
+// or zero if the run time is not limited.
+func (b *budget) acquire() (time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.maxCommands > 0 && b.commands >= b.maxCommands {
+		return 0, ErrBudgetExceeded
+	}
+	if b.maxDuration > 0 && b.used >= b.maxDuration {
+		return 0, ErrBudgetExceeded
+	}
+	b.commands++
+	if b.maxDuration > 0 {
+		return b.maxDuration - b.used, nil
+	}
+	return 0, nil
+}
+
+func (b *budget) release(d time.Duration) {
+	b.mu.Lock()
+	b.used += d
+	b.mu.Unlock()
+}
+
+// acquireBudget charges the command against the budget of its context and
+// kills it once its share of the run time is used up.
+func (c *Command) acquireBudget() error {
+	b, ok := c.ctx.Value(budgetKey{}).(*budget)
+	if !ok {
+		return nil
+	}
+	remaining, err := b.acquire()
+	if err != nil {
+		return err
+	}
+	started := time.Now()
+	var timer *time.Timer
+	if remaining > 0 {
+		timer = time.AfterFunc(remaining, func() { c.CancelWithReason(ReasonBudget) })
+	}
+	c.cleanups = append(c.cleanups, func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		b.release(time.Since(started))
+	})
+	return nil
+}
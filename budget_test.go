@@ -0,0 +1,48 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCommandBudget(t *testing.T) {
+	testCases := []struct {
+		name        string
+		maxCommands int
+		maxDuration time.Duration
+		args        []interface{}
+		runs        int
+		reasons     []string
+		err         error
+	}{
+		{name: "unlimited", args: []interface{}{withCommandService(&CommandServiceMock{})}, runs: 3, reasons: []string{"", "", ""}},
+		{name: "commands", maxCommands: 2, args: []interface{}{withCommandService(&CommandServiceMock{})}, runs: 3, reasons: []string{"", ""}, err: ErrBudgetExceeded},
+		{name: "duration", maxDuration: 10 * time.Millisecond, args: []interface{}{"-c", "exec sleep 10"}, runs: 2, reasons: []string{ReasonBudget}, err: ErrBudgetExceeded},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx := WithBudget(context.Background(), tc.maxCommands, tc.maxDuration)
+			reasons := []string{}
+			var err error
+			for i := 0; i < tc.runs && err == nil; i++ {
+				var cmd *Command
+				cmd, err = NewCommand(ctx, "sh", tc.args...)
+				validateError(tt, nil, err)
+				var events <-chan Event
+				if events, err = cmd.Execute(); err != nil {
+					break
+				}
+				for range events {
+				}
+				state := <-cmd.Wait()
+				reasons = append(reasons, state.CancelReason())
+			}
+			validateError(tt, tc.err, err)
+			validateResult(tt, tc.reasons, reasons)
+		})
+	}
+}
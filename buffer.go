@@ -0,0 +1,118 @@
+package command
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithEventBuffer buffers up to n events between the output readers and the
+// consumer, so that the command is not blocked on output while the consumer
+// is momentarily slow. The events and their order are not changed.
+func WithEventBuffer(n int) Option {
+
+	return func(c *Command) error {
+		c.record("WithEventBuffer", n)
+		if n < 0 {
+			return fmt.Errorf("invalid event buffer: %d", n)
+		}
+		c.eventBuffer = n
+		return nil
+	}
+}
+
+// OverflowPolicy defines what happens to output lines in streaming mode if
+// the event buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the output readers until the consumer catches
+	// up, the default.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropOldest drops the oldest buffered line.
+	OverflowDropOldest
+
+	// OverflowDropNewest drops the new line.
+	OverflowDropNewest
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowDropNewest:
+		return "drop-newest"
+	}
+	return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+}
+
+// WithOverflowPolicy sets the policy for output lines in streaming mode if
+// the event buffer (see WithEventBuffer) is full, e.g. to drop lines for
+// dashboards which only care about recent output instead of blocking the
+// command. The number of dropped lines is reported by Stats.DroppedLines.
+// Without an event buffer, lines are dropped unless the consumer is
+// waiting for them. Only output lines are dropped, other events such as
+// lifecycle, error, match and progress events are always delivered. Read
+// errors are reported by State.ReadErrors regardless.
+func WithOverflowPolicy(p OverflowPolicy) Option {
+
+	return func(c *Command) error {
+		c.record("WithOverflowPolicy", p)
+		if p < OverflowBlock || p > OverflowDropNewest {
+			return fmt.Errorf("invalid overflow policy: %d", int(p))
+		}
+		c.overflow = p
+		return nil
+	}
+}
+
+// offer sends the line event to out according to the overflow policy. It
+// returns false if ctx is done.
+func (c *Command) offer(ctx context.Context, out chan Event, event Event) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case out <- event:
+		return true
+	default:
+	}
+	c.stats.addDropped()
+	if c.overflow == OverflowDropOldest && evictLine(out) {
+		// the reader is the only sender, so the evicted slot is still free
+		out <- event
+	}
+	// otherwise no line is buffered, the new line is dropped instead
+	return true
+}
+
+// evictLine removes the oldest line event buffered in out and keeps all
+// other events in order. It returns false if no line is buffered.
+func evictLine(out chan Event) bool {
+	buffered := make([]Event, 0, len(out))
+Drain:
+	for {
+		select {
+		case evt := <-out:
+			buffered = append(buffered, evt)
+		default:
+			break Drain
+		}
+	}
+	evicted := false
+	for _, evt := range buffered {
+		if !evicted && isLineEvent(evt) {
+			evicted = true
+			continue
+		}
+		out <- evt
+	}
+	return evicted
+}
+
+// isLineEvent reports whether evt carries output lines.
+func isLineEvent(evt Event) bool {
+	kind := evt.Kind()
+	return kind == EventStdout || kind == EventStderr
+}
@@ -0,0 +1,142 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventBuffer(t *testing.T) {
+	testCases := []struct {
+		name   string
+		buffer int
+		lines  int
+		done   bool
+		err    error
+	}{
+		{name: "buffered", buffer: 100, lines: 50, done: true},
+		{name: "unbuffered", buffer: 0, lines: 50},
+		{name: "invalid", buffer: -1, err: errors.New("invalid event buffer: -1")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			output := []string{}
+			for i := 1; i <= tc.lines; i++ {
+				output = append(output, fmt.Sprint(i))
+			}
+			mock := &CommandServiceMock{stdout: strings.Join(output, "\n")}
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(mock), WithStreaming(), WithEventBuffer(tc.buffer))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			// the command completes without a consumer only if its output fits
+			// into the buffer
+			timeout := time.Millisecond
+			if tc.done {
+				timeout = 0
+			}
+			ctx, cancel := createTestContext(timeout)
+			defer cancel()
+			_, err = cmd.WaitContext(ctx)
+			validateBool(tt, tc.done, err == nil)
+			lines := 0
+			for event := range events {
+				validateResult(tt, []string{fmt.Sprint(lines + 1)}, event.Data().Stdout())
+				lines++
+			}
+			validateResult(tt, tc.lines, lines)
+		})
+	}
+}
+
+func TestOverflowPolicy(t *testing.T) {
+	testCases := []struct {
+		name    string
+		policy  OverflowPolicy
+		stdout  []string
+		dropped int64
+		err     error
+	}{
+		{name: "block", policy: OverflowBlock, stdout: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}},
+		{name: "dropOldest", policy: OverflowDropOldest, stdout: []string{"9", "10"}, dropped: 8},
+		{name: "dropNewest", policy: OverflowDropNewest, stdout: []string{"1", "2"}, dropped: 8},
+		{name: "invalid", policy: OverflowPolicy(5), err: errors.New("invalid overflow policy: 5")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			mock := &CommandServiceMock{stdout: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"}
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(mock), WithStreaming(), WithEventBuffer(2), WithOverflowPolicy(tc.policy))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			if tc.policy != OverflowBlock {
+				// complete the command before consuming its events
+				_, err = cmd.WaitContext(context.Background())
+				validateError(tt, nil, err)
+			}
+			stdout := []string{}
+			for event := range events {
+				stdout = append(stdout, event.Data().Stdout()...)
+			}
+			state := <-cmd.Wait()
+			validateResult(tt, tc.stdout, stdout)
+			validateResult(tt, tc.dropped, state.Stats().DroppedLines)
+		})
+	}
+}
+
+func TestOfferDropOldest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		buffered []EventKind
+		expect   []EventKind
+		dropped  int64
+	}{
+		{name: "line", buffered: []EventKind{EventStdout, EventStderr}, expect: []EventKind{EventStderr, EventStdout}, dropped: 1},
+		{name: "keepStarted", buffered: []EventKind{EventStarted, EventStdout}, expect: []EventKind{EventStarted, EventStdout}, dropped: 1},
+		{name: "keepOrder", buffered: []EventKind{EventError, EventStderr, EventMatch}, expect: []EventKind{EventError, EventMatch, EventStdout}, dropped: 1},
+		{name: "noLine", buffered: []EventKind{EventStarted, EventError}, expect: []EventKind{EventStarted, EventError}, dropped: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(&CommandServiceMock{}), WithOverflowPolicy(OverflowDropOldest))
+			validateError(tt, nil, err)
+			out := make(chan Event, len(tc.buffered))
+			for _, kind := range tc.buffered {
+				out <- testEventOfKind(cmd, kind)
+			}
+			validateBool(tt, true, cmd.offer(context.Background(), out, testEventOfKind(cmd, EventStdout)))
+			close(out)
+			got := []EventKind{}
+			for evt := range out {
+				got = append(got, evt.Kind())
+			}
+			validateResult(tt, tc.expect, got)
+			validateResult(tt, tc.dropped, cmd.stats.snapshot().DroppedLines)
+		})
+	}
+}
+
+func testEventOfKind(c *Command, kind EventKind) Event {
+	switch kind {
+	case EventStdout:
+		return c.newEvent(newStreamData("out", false), nil)
+	case EventStderr:
+		return c.newEvent(newStreamData("err", true), nil)
+	case EventError:
+		return c.newEvent(newStreamData("", false), ErrOutputTruncated)
+	}
+	return c.lifecycleEvent(kind, nil)
+}
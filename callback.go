@@ -0,0 +1,52 @@
+package command
+
+import "fmt"
+
+// OnStdoutLine calls fn for every stdout line, e.g. to use the package in a
+// callback oriented integration without a select loop. The callbacks are
+// called one at a time on the goroutine which forwards the events, in the
+// order of the events and before an event is delivered, so a slow callback
+// holds back the output. Events and results work unchanged; combine it with
+// WithDiscardOutput if they are not needed. Segments of a line split by
+// WithUnboundedLines are passed separately, chunks not at all.
+func OnStdoutLine(fn func(line string)) Option {
+
+	return func(c *Command) error {
+		c.record("OnStdoutLine", funcValue)
+		if fn == nil {
+			return fmt.Errorf("callback cannot be nil")
+		}
+		c.onStdout = fn
+		return nil
+	}
+}
+
+// OnStderrLine calls fn for every stderr line (see OnStdoutLine).
+func OnStderrLine(fn func(line string)) Option {
+
+	return func(c *Command) error {
+		c.record("OnStderrLine", funcValue)
+		if fn == nil {
+			return fmt.Errorf("callback cannot be nil")
+		}
+		c.onStderr = fn
+		return nil
+	}
+}
+
+// callLineCallbacks passes the lines of data to the line callbacks.
+func (c *Command) callLineCallbacks(data Data) {
+	if data.Bytes() != nil {
+		return
+	}
+	if c.onStdout != nil {
+		for _, line := range data.Stdout() {
+			c.onStdout(line)
+		}
+	}
+	if c.onStderr != nil {
+		for _, line := range data.Stderr() {
+			c.onStderr(line)
+		}
+	}
+}
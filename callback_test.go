@@ -0,0 +1,47 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLineCallbacks(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []interface{}
+		stdout []string
+		stderr []string
+	}{
+		{name: "result", stdout: []string{"1", "3"}, stderr: []string{"2"}},
+		{name: "stream", args: []interface{}{WithStreaming()}, stdout: []string{"1", "3"}, stderr: []string{"2"}},
+		{name: "discard", args: []interface{}{WithDiscardOutput()}, stdout: []string{"1", "3"}, stderr: []string{"2"}},
+		{name: "chunks", args: []interface{}{WithChunkStreaming(16)}, stdout: []string{}, stderr: []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			stdout := []string{}
+			stderr := []string{}
+			args := append([]interface{}{
+				withCommandService(&CommandServiceMock{stdout: "1\n3\n", stderr: "2\n"}),
+				OnStdoutLine(func(line string) { stdout = append(stdout, line) }),
+				OnStderrLine(func(line string) { stderr = append(stderr, line) }),
+			}, tc.args...)
+			state, err := Run(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			validateResult(tt, 0, state.ExitCode())
+			validateResult(tt, tc.stdout, stdout)
+			validateResult(tt, tc.stderr, stderr)
+		})
+	}
+}
+
+func TestLineCallbacksNil(t *testing.T) {
+	_, err := NewCommand(context.Background(), "true", OnStdoutLine(nil))
+	validateError(t, errors.New("callback cannot be nil"), err)
+	_, err = NewCommand(context.Background(), "true", OnStderrLine(nil))
+	validateError(t, errors.New("callback cannot be nil"), err)
+}
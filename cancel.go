@@ -0,0 +1,79 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// Cancellation reasons set on the State if the command was stopped by its
+// context rather than by CancelWithReason.
+const (
+	ReasonDeadline = "deadline"
+	ReasonCanceled = "canceled"
+)
+
+// CancelError is returned if a command was cancelled by CancelWithReason.
+type CancelError struct {
+	Reason string
+}
+
+func (e *CancelError) Error() string {
+	return fmt.Sprintf("command canceled: %s", e.Reason)
+}
+
+// CancelWithReason kills the command and records reason, which is reported
+// by the final State and by the last event. If the command has not been
+// started yet, Execute fails with a *CancelError. Only the first reason is
+// kept.
+func (c *Command) CancelWithReason(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancelReason == "" {
+		c.cancelReason = reason
+	}
+	if cmd, ok := c.cmd.(*exec.Cmd); ok && cmd.Process != nil {
+		c.kill(cmd)
+	}
+}
+
+// startProcess starts the process unless the command has been cancelled.
+func (c *Command) startProcess() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancelReason != "" {
+		return &CancelError{Reason: c.cancelReason}
+	}
+	if err := c.cmd.Start(); err != nil {
+		return withKind(c.notFound(err), ErrStartFailed)
+	}
+	return nil
+}
+
+// cancelError returns a *CancelError if the command has been cancelled by
+// CancelWithReason.
+func (c *Command) cancelError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancelReason != "" {
+		return &CancelError{Reason: c.cancelReason}
+	}
+	return nil
+}
+
+// reason returns the cancellation reason, taking the context into account.
+func (c *Command) reason() string {
+	c.mu.Lock()
+	reason := c.cancelReason
+	c.mu.Unlock()
+	if reason != "" {
+		return reason
+	}
+	switch c.ctx.Err() {
+	case context.DeadlineExceeded:
+		return ReasonDeadline
+	case context.Canceled:
+		return ReasonCanceled
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCommandCancelWithReason(t *testing.T) {
+	testCases := []struct {
+		name   string
+		stream bool
+		reason string
+		expect string
+	}{
+		{name: "stream", stream: true, reason: "user", expect: "user"},
+		{name: "nostream", reason: "policy", expect: "policy"},
+		{name: "deadline", expect: ReasonDeadline},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx := newExpiringContext()
+			defer ctx.expire()
+			args := []interface{}{"-c", "echo started; exec sleep 10"}
+			if tc.stream {
+				args = append(args, WithStreaming())
+			}
+			cmd, err := NewCommand(ctx, "sh", args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			if tc.reason != "" {
+				for cmd.stats.snapshot().StdoutLines == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				cmd.CancelWithReason(tc.reason)
+				cmd.CancelWithReason("ignored")
+			} else {
+				ctx.expire()
+			}
+			var last Event
+			for event := range events {
+				last = event
+			}
+			state := <-cmd.Wait()
+			validateResult(tt, tc.expect, state.CancelReason())
+			validateResult(tt, -1, state.ExitCode())
+			if tc.reason != "" {
+				validateError(tt, &CancelError{Reason: tc.reason}, last.Error())
+			}
+		})
+	}
+}
+
+func TestCommandCancelBeforeExecute(t *testing.T) {
+	cmd, err := NewCommand(context.Background(), "sh", "-c", "exit")
+	validateError(t, nil, err)
+	cmd.CancelWithReason("shutdown")
+	_, err = cmd.Execute()
+	validateError(t, &CancelError{Reason: "shutdown"}, err)
+}
+
+func TestCommandSignal(t *testing.T) {
+	testCases := []struct {
+		name     string
+		script   string
+		signaled bool
+		signal   syscall.Signal
+	}{
+		{name: "exit", script: "exit 3"},
+		{name: "segv", script: "kill -SEGV $$", signaled: true, signal: syscall.SIGSEGV},
+		{name: "kill", script: "kill -KILL $$", signaled: true, signal: syscall.SIGKILL},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", "-c", tc.script)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			for range events {
+			}
+			state := <-cmd.Wait()
+			validateResult(tt, tc.signaled, state.Signaled())
+			validateResult(tt, tc.signal, state.Signal())
+		})
+	}
+}
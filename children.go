@@ -0,0 +1,45 @@
+package command
+
+// ProcessInfo describes a process of the command's process tree.
+type ProcessInfo struct {
+	Pid  int
+	PPid int
+
+	// Name is the executable name of the process.
+	Name string
+
+	// RSS is the resident set size in bytes.
+	RSS int64
+}
+
+// Children returns the descendant processes of the running command, e.g.
+// the processes spawned by a wrapped script. It is only supported on Linux.
+func (c *Command) Children() ([]ProcessInfo, error) {
+	pid := c.Pid()
+	if pid == 0 {
+		return nil, ErrNotStarted
+	}
+	procs, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+	return descendants(procs, pid), nil
+}
+
+// descendants returns all processes below pid in breadth-first order.
+func descendants(procs []ProcessInfo, pid int) []ProcessInfo {
+	children := make(map[int][]ProcessInfo)
+	for _, p := range procs {
+		children[p.PPid] = append(children[p.PPid], p)
+	}
+	result := []ProcessInfo{}
+	queue := []int{pid}
+	for len(queue) > 0 {
+		for _, child := range children[queue[0]] {
+			result = append(result, child)
+			queue = append(queue, child.Pid)
+		}
+		queue = queue[1:]
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listProcesses reads all processes from /proc. Processes which exit while
+// reading are skipped.
+func listProcesses() ([]ProcessInfo, error) {
+	entries, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	pageSize := int64(os.Getpagesize())
+	procs := make([]ProcessInfo, 0, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			continue
+		}
+		if p, err := parseProcStat(string(data), pageSize); err == nil {
+			procs = append(procs, p)
+		}
+	}
+	return procs, nil
+}
+
+// parseProcStat parses a /proc/<pid>/stat line. The name is enclosed in
+// parentheses and may itself contain spaces and parentheses.
+func parseProcStat(stat string, pageSize int64) (ProcessInfo, error) {
+	open := strings.IndexByte(stat, '(')
+	end := strings.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return ProcessInfo{}, fmt.Errorf("invalid stat: %q", stat)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(stat[:open]))
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	// fields after the name start with state (field 3)
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return ProcessInfo{}, fmt.Errorf("invalid stat: %q", stat)
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	rss, err := strconv.ParseInt(fields[21], 10, 64)
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	return ProcessInfo{Pid: pid, PPid: ppid, Name: stat[open+1 : end], RSS: rss * pageSize}, nil
+}
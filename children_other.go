@@ -0,0 +1,9 @@
+// +build !linux
+
+package command
+
+import "errors"
+
+func listProcesses() ([]ProcessInfo, error) {
+	return nil, errors.New("listing child processes is only supported on linux")
+}
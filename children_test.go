@@ -0,0 +1,67 @@
+// +build !integration
+// +build unit
+// +build linux
+
+package command
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDescendants(t *testing.T) {
+	procs := []ProcessInfo{
+		{Pid: 1, PPid: 0}, {Pid: 10, PPid: 1}, {Pid: 11, PPid: 10}, {Pid: 12, PPid: 10}, {Pid: 13, PPid: 11}, {Pid: 20, PPid: 1},
+	}
+	testCases := []struct {
+		name   string
+		pid    int
+		expect []int
+	}{
+		{name: "tree", pid: 10, expect: []int{11, 12, 13}},
+		{name: "leaf", pid: 13, expect: []int{}},
+		{name: "unknown", pid: 99, expect: []int{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			got := []int{}
+			for _, p := range descendants(procs, tc.pid) {
+				got = append(got, p.Pid)
+			}
+			validateResult(tt, tc.expect, got)
+		})
+	}
+}
+
+func TestCommandChildren(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd, err := NewCommand(ctx, "sh", "-c", "sleep 10 & sleep 10 & echo started; wait")
+	validateError(t, nil, err)
+	_, err = cmd.Children()
+	validateError(t, ErrNotStarted, err)
+
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	// wait until the children have been spawned and exec'd
+	var children []ProcessInfo
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		children, err = cmd.Children()
+		validateError(t, nil, err)
+		if len(children) == 2 && children[0].Name == "sleep" && children[1].Name == "sleep" {
+			break
+		}
+	}
+	validateResult(t, 2, len(children))
+	for _, child := range children {
+		validateResult(t, "sleep", child.Name)
+		validateBool(t, true, child.RSS > 0)
+		syscall.Kill(child.Pid, syscall.SIGKILL)
+	}
+	cancel()
+	for range events {
+	}
+	<-cmd.Wait()
+}
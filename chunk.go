@@ -0,0 +1,60 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// WithChunkStreaming enables streaming (see WithStreaming) of raw chunks of
+// at most chunkSize bytes instead of lines, e.g. for binary output of tar or
+// pg_dump. The chunk is returned by Data.Bytes; Data.Stdout and Data.Stderr
+// return it as a single string. Chunks are counted as lines by Stats, and
+// secrets are not redacted in chunks.
+func WithChunkStreaming(chunkSize int) Option {
+
+	return func(c *Command) error {
+		c.record("WithChunkStreaming", chunkSize)
+		if chunkSize < 1 {
+			return fmt.Errorf("invalid chunk size: %d", chunkSize)
+		}
+		c.stream = true
+		c.chunkSize = chunkSize
+		return nil
+	}
+}
+
+// readChunks reads inStream in chunks of at most size bytes.
+func readChunks(ctx context.Context, inStream io.Reader, errStream bool, size int) <-chan streamData {
+	outStream := make(chan streamData)
+
+	go func() {
+		defer close(outStream)
+		for {
+			buf := make([]byte, size)
+			n, err := inStream.Read(buf)
+			if n > 0 {
+				event := streamData{data: string(buf[:n]), chunk: buf[:n], isStderr: errStream, at: time.Now()}
+				select {
+				case <-ctx.Done():
+					return
+				case outStream <- event:
+				}
+			}
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				event := *newStreamData("", errStream)
+				event.err = newReadError(errStream, err)
+				select {
+				case <-ctx.Done():
+				case outStream <- event:
+				}
+				return
+			}
+		}
+	}()
+	return outStream
+}
@@ -0,0 +1,50 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestChunkStreaming(t *testing.T) {
+	testCases := []struct {
+		name   string
+		size   int
+		stdout string
+		stderr string
+		err    error
+	}{
+		{name: "binary", size: 4096, stdout: "a\x00b\n\nc", stderr: "e\n"},
+		{name: "small", size: 2, stdout: "a\x00b\n\nc", stderr: "e\n"},
+		{name: "invalid", size: 0, err: errors.New("invalid chunk size: 0")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(&CommandServiceMock{stdout: tc.stdout, stderr: tc.stderr}), WithChunkStreaming(tc.size))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			var stdout, stderr []byte
+			for event := range events {
+				validateError(tt, nil, event.Error())
+				chunk := event.Data().Bytes()
+				validateBool(tt, true, len(chunk) > 0 && len(chunk) <= tc.size)
+				if len(event.Data().Stderr()) > 0 {
+					stderr = append(stderr, chunk...)
+				} else {
+					validateResult(tt, []string{string(chunk)}, event.Data().Stdout())
+					stdout = append(stdout, chunk...)
+				}
+			}
+			validateResult(tt, tc.stdout, string(stdout))
+			validateResult(tt, tc.stderr, string(stderr))
+			validateResult(tt, len(tc.stdout), int((<-cmd.Wait()).Stats().StdoutBytes))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package command
+
+import (
+	"regexp"
+	"sync"
+)
+
+// ErrorClass categorizes the failure of a script run by an interpreter (see
+// WithErrorClassification).
+type ErrorClass int
+
+// Error classes. Syntax errors take precedence over runtime errors.
+const (
+	ErrorClassNone ErrorClass = iota
+	ErrorClassRuntime
+	ErrorClassSyntax
+)
+
+func (e ErrorClass) String() string {
+	switch e {
+	case ErrorClassRuntime:
+		return "runtime error"
+	case ErrorClassSyntax:
+		return "syntax error"
+	}
+	return "none"
+}
+
+// ErrorPattern maps stderr lines matching Pattern to Class.
+type ErrorPattern struct {
+	Class   ErrorClass
+	Pattern *regexp.Regexp
+}
+
+// DefaultErrorPatterns recognize syntax errors and uncaught exceptions of
+// sh/bash, Python and Node.js.
+var DefaultErrorPatterns = []ErrorPattern{
+	{Class: ErrorClassSyntax, Pattern: regexp.MustCompile(`(?i)\bsyntax error\b`)},
+	{Class: ErrorClassSyntax, Pattern: regexp.MustCompile(`^(SyntaxError|IndentationError|TabError)\b`)},
+	{Class: ErrorClassRuntime, Pattern: regexp.MustCompile(`^Traceback \(most recent call last\):`)},
+	{Class: ErrorClassRuntime, Pattern: regexp.MustCompile(`^(Uncaught )?[A-Z][A-Za-z]*(Error|Exception)\b`)},
+	{Class: ErrorClassRuntime, Pattern: regexp.MustCompile(`: (command not found|not found|Permission denied|unbound variable|parameter not set)$`)},
+}
+
+// WithErrorClassification matches the stderr lines of the command against
+// patterns and reports the most severe match by State.ErrorClass if the
+// command exits with a non-zero code. Without patterns DefaultErrorPatterns
+// are used.
+func WithErrorClassification(patterns ...ErrorPattern) Option {
+
+	return func(c *Command) error {
+		if len(patterns) == 0 {
+			patterns = DefaultErrorPatterns
+		}
+		c.record("WithErrorClassification", len(patterns), "patterns")
+		c.classifier = &errorClassifier{patterns: patterns}
+		return nil
+	}
+}
+
+// errorClassifier records the most severe class of the observed lines.
+type errorClassifier struct {
+	patterns []ErrorPattern
+	mu       sync.Mutex
+	class    ErrorClass
+}
+
+func (e *errorClassifier) observe(line string) {
+	for _, p := range e.patterns {
+		if p.Pattern.MatchString(line) {
+			e.mu.Lock()
+			if p.Class > e.class {
+				e.class = p.Class
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+func (e *errorClassifier) result() ErrorClass {
+	if e == nil {
+		return ErrorClassNone
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.class
+}
@@ -0,0 +1,63 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestCommandErrorClassification(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mock   *CommandServiceMock
+		opts   []interface{}
+		expect ErrorClass
+	}{
+		{name: "shSyntax", mock: &CommandServiceMock{stderr: "sh: 1: Syntax error: \"then\" unexpected\n", errWait: true}, opts: []interface{}{WithErrorClassification()}, expect: ErrorClassSyntax},
+		{name: "shRuntime", mock: &CommandServiceMock{stderr: "sh: 1: command-which-does-not-exist: not found\n", errWait: true}, opts: []interface{}{WithErrorClassification()}, expect: ErrorClassRuntime},
+		{
+			name:   "python",
+			mock:   &CommandServiceMock{stderr: "Traceback (most recent call last):\n  File \"x.py\", line 1\nZeroDivisionError: division by zero\n", errWait: true},
+			opts:   []interface{}{WithErrorClassification()},
+			expect: ErrorClassRuntime,
+		},
+		{
+			name:   "pythonSyntax",
+			mock:   &CommandServiceMock{stderr: "  File \"x.py\", line 1\nSyntaxError: invalid syntax\n", errWait: true},
+			opts:   []interface{}{WithErrorClassification()},
+			expect: ErrorClassSyntax,
+		},
+		{
+			name:   "node",
+			mock:   &CommandServiceMock{stderr: "TypeError: x is not a function\n    at main.js:1:1\n", errWait: true},
+			opts:   []interface{}{WithErrorClassification()},
+			expect: ErrorClassRuntime,
+		},
+		{name: "success", mock: &CommandServiceMock{stderr: "SyntaxError: logged\n"}, opts: []interface{}{WithErrorClassification()}, expect: ErrorClassNone},
+		{name: "stdout", mock: &CommandServiceMock{stdout: "SyntaxError: logged\n", errWait: true}, opts: []interface{}{WithErrorClassification()}, expect: ErrorClassNone},
+		{name: "disabled", mock: &CommandServiceMock{stderr: "sh: 1: Syntax error: \"then\" unexpected\n", errWait: true}, expect: ErrorClassNone},
+		{
+			name:   "custom",
+			mock:   &CommandServiceMock{stderr: "FATAL: out of cheese\n", errWait: true},
+			opts:   []interface{}{WithErrorClassification(ErrorPattern{Class: ErrorClassRuntime, Pattern: regexp.MustCompile(`^FATAL:`)})},
+			expect: ErrorClassRuntime,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append([]interface{}{withCommandService(tc.mock)}, tc.opts...)...)
+			validateError(tt, nil, err)
+			cmd.processState = &processStateMock{exit: 1}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			for range events {
+			}
+			state := <-cmd.Wait()
+			validateResult(tt, tc.expect, state.ErrorClass())
+			validateResult(tt, tc.expect.String(), state.ErrorClass().String())
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package command
+
+import "context"
+
+// ReasonRestart is the cancellation reason of executions stopped by
+// Restart.
+const ReasonRestart = "restart"
+
+// Clone returns a new Command with the context, name, arguments and options
+// of c, which can be executed independently of c. Options are applied
+// again, so options holding single-use resources, like WithStdin or
+// WithExtraFiles, need to be replaced by the caller.
+func (c *Command) Clone() (*Command, error) {
+	return NewCommand(c.ctx, c.name, c.rawArgs...)
+}
+
+// Restart stops the current execution of c, if any, and executes a clone
+// of it. The caller must not consume the events of c concurrently; the
+// remaining events are discarded. It returns the new Command and its
+// events.
+func (c *Command) Restart() (*Command, <-chan Event, error) {
+	if c.events != nil {
+		c.CancelWithReason(ReasonRestart)
+		c.Drain(context.Background())
+	}
+	clone, err := c.Clone()
+	if err != nil {
+		return nil, nil, err
+	}
+	events, err := clone.Execute()
+	if err != nil {
+		return nil, nil, err
+	}
+	return clone, events, nil
+}
@@ -0,0 +1,63 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCommandClone(t *testing.T) {
+	testCases := []struct {
+		name   string
+		script string
+		stdout []string
+	}{
+		{name: "echo", script: "echo 1", stdout: []string{"1"}},
+		{name: "env", script: "echo $CLONE", stdout: []string{"x"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", "-c", tc.script, WithEnv([]string{"CLONE=x"}))
+			validateError(tt, nil, err)
+			clone, err := cmd.Clone()
+			validateError(tt, nil, err)
+			validateBool(tt, true, clone != cmd)
+			validateResult(tt, cmd.Options(), clone.Options())
+			for _, c := range []*Command{cmd, clone} {
+				events, err := c.Execute()
+				validateError(tt, nil, err)
+				event := <-events
+				validateResult(tt, tc.stdout, event.Data().Stdout())
+			}
+		})
+	}
+}
+
+func TestCommandRestart(t *testing.T) {
+	testCases := []struct {
+		name    string
+		execute bool
+		reason  string
+	}{
+		{name: "notStarted"},
+		{name: "running", execute: true, reason: ReasonRestart},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", "-c", "echo 1; exec sleep 10", WithStreaming())
+			validateError(tt, nil, err)
+			if tc.execute {
+				_, err = cmd.Execute()
+				validateError(tt, nil, err)
+			}
+			clone, events, err := cmd.Restart()
+			validateError(tt, nil, err)
+			defer clone.CancelWithReason("test done")
+			validateResult(tt, tc.reason, cmd.reason())
+			event := <-events
+			validateResult(tt, []string{"1"}, event.Data().Stdout())
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package command
+
+import "context"
+
+// ReasonClosed is the cancellation reason of executions stopped by Close.
+const ReasonClosed = "closed"
+
+// Close releases all resources of the command: if the output is still being
+// read, the process is killed with reason ReasonClosed, the remaining events
+// and the final state are drained, the pipes are closed and spill files (see
+// WithSpill) which are not kept are removed. A command which has not been executed can't be
+// executed after Close. Close is meant to be deferred by callers which might
+// abandon the event channel; it is safe to call multiple times, but must not
+// be called concurrently with Execute or with a consumer of the events.
+func (c *Command) Close() error {
+	c.closeOnce.Do(func() {
+		if c.events == nil {
+			c.CancelWithReason(ReasonClosed)
+			c.cleanup()
+			return
+		}
+		select {
+		case <-c.readDone:
+		default:
+			c.CancelWithReason(ReasonClosed)
+		}
+		c.Drain(context.Background())
+		if c.spill != nil {
+			c.spill.release()
+		}
+	})
+	return nil
+}
@@ -0,0 +1,52 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCommandClose(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []interface{}
+		execute bool
+		consume int
+		reason  string
+		err     error
+	}{
+		{name: "notExecuted", args: []interface{}{withCommandService(&CommandServiceMock{stdout: "1\n"})}, reason: ReasonClosed, err: errors.New("command canceled: closed")},
+		{name: "abandoned", args: []interface{}{"-c", "echo 1; exec sleep 10"}, execute: true, consume: 1, reason: ReasonClosed},
+		{name: "unread", args: []interface{}{"-c", "echo 1; exec sleep 10"}, execute: true, reason: ReasonClosed},
+		{name: "completed", args: []interface{}{withCommandService(&CommandServiceMock{stdout: "1\n"})}, execute: true, consume: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append(tc.args, WithStreaming())...)
+			validateError(tt, nil, err)
+			if tc.execute {
+				events, err := cmd.Execute()
+				validateError(tt, nil, err)
+				for i := 0; i < tc.consume; i++ {
+					<-events
+				}
+			}
+			validateError(tt, nil, cmd.Close())
+			validateError(tt, nil, cmd.Close())
+			validateResult(tt, tc.reason, cmd.reason())
+			if !tc.execute {
+				_, err = cmd.Execute()
+				validateError(tt, tc.err, err)
+				return
+			}
+			select {
+			case <-cmd.processDone:
+			default:
+				tt.Error("expected resources to be released")
+			}
+		})
+	}
+}
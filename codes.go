@@ -0,0 +1,55 @@
+package command
+
+import "errors"
+
+// Coder is implemented by the errors of this package. ErrorCode returns a
+// stable, machine-readable code like "START_FAILED" which, unlike the error
+// message, does not change between releases.
+type Coder interface {
+	ErrorCode() string
+}
+
+// CodeUnknown is returned by ErrorCode for errors without a code.
+const CodeUnknown = "UNKNOWN"
+
+// ErrorCode returns the code of the innermost error in err's chain which
+// implements Coder, i.e. the most specific one. It returns an empty string
+// if err is nil and CodeUnknown if no error in the chain has a code.
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	code := CodeUnknown
+	for ; err != nil; err = errors.Unwrap(err) {
+		if c, ok := err.(Coder); ok {
+			code = c.ErrorCode()
+		}
+	}
+	return code
+}
+
+// codedError is a sentinel error with a code.
+type codedError struct {
+	code string
+	msg  string
+}
+
+func newCodedError(code, msg string) error {
+	return &codedError{code: code, msg: msg}
+}
+
+func (e *codedError) Error() string     { return e.msg }
+func (e *codedError) ErrorCode() string { return e.code }
+
+func (e *RateLimitError) ErrorCode() string { return "RATE_LIMITED" }
+func (e *StderrError) ErrorCode() string    { return "STDERR_OUTPUT" }
+func (e *CancelError) ErrorCode() string    { return "CANCELED" }
+func (e *DirError) ErrorCode() string       { return "INVALID_DIR" }
+func (e *NotFoundError) ErrorCode() string  { return "NOT_FOUND" }
+func (e *VersionError) ErrorCode() string   { return "VERSION_TOO_OLD" }
+func (e *ExitCodeError) ErrorCode() string  { return "NON_ZERO_EXIT" }
+
+// ErrorCode returns the code of the most specific kind.
+func (e *kindError) ErrorCode() string {
+	return ErrorCode(e.kinds[len(e.kinds)-1])
+}
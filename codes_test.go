@@ -0,0 +1,44 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		expect string
+	}{
+		{name: "nil", expect: ""},
+		{name: "unknown", err: errors.New("other"), expect: CodeUnknown},
+		{name: "sentinel", err: ErrPoolClosed, expect: "POOL_CLOSED"},
+		{name: "wrappedSentinel", err: fmt.Errorf("network isolation: %w", ErrUnsupported), expect: "UNSUPPORTED"},
+		{name: "typed", err: &StderrError{Lines: 1}, expect: "STDERR_OUTPUT"},
+		{name: "kind", err: withKind(errors.New("signal: killed"), ErrNonZeroExit, ErrDeadline), expect: "DEADLINE_EXCEEDED"},
+		{name: "innermost", err: withKind(&NotFoundError{Name: "x", Err: errors.New("not found")}, ErrStartFailed), expect: "NOT_FOUND"},
+		{name: "exitCode", err: &ExitCodeError{Code: 1, Err: errors.New("exit status 1")}, expect: "NON_ZERO_EXIT"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			validateResult(tt, tc.expect, ErrorCode(tc.err))
+		})
+	}
+}
+
+func TestCommandErrorCode(t *testing.T) {
+	ctx := newExpiringContext()
+	cmd, err := NewCommand(ctx, "sh", "-c", "exec sleep 10", WithErrOnNonZeroExit())
+	validateError(t, nil, err)
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	ctx.expire()
+	for range events {
+	}
+	validateResult(t, "DEADLINE_EXCEEDED", ErrorCode((<-cmd.Wait()).Error()))
+}
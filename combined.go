@@ -0,0 +1,37 @@
+package command
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+)
+
+// WithCombinedPipe writes stdout and stderr of the command to a single pipe,
+// like 2>&1, so that the events reflect the exact order in which the
+// command wrote its output. All output is reported as stdout. It is only
+// supported for local processes.
+func WithCombinedPipe() Option {
+
+	return func(c *Command) error {
+		c.record("WithCombinedPipe")
+		c.combinedPipe = true
+		return nil
+	}
+}
+
+// stderrPipe returns the stderr pipe of the command. With WithCombinedPipe,
+// stderr is redirected to the stdout pipe, which must have been created
+// before, and an empty reader is returned.
+func (c *Command) stderrPipe() (io.ReadCloser, error) {
+	if !c.combinedPipe {
+		return c.cmd.StderrPipe()
+	}
+	cmd, ok := c.cmd.(*exec.Cmd)
+	if !ok {
+		return nil, errors.New("combined pipe requires a local process")
+	}
+	cmd.Stderr = cmd.Stdout
+	return ioutil.NopCloser(strings.NewReader("")), nil
+}
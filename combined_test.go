@@ -0,0 +1,43 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCombinedPipe(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []interface{}
+		stdout []string
+		err    error
+	}{
+		{name: "ordered", stdout: []string{"1", "2", "3", "4", "5", "6"}},
+		{name: "backend", args: []interface{}{withCommandService(&CommandServiceMock{})}, err: errors.New("combined pipe requires a local process")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			script := "echo 1; echo 2 >&2; echo 3; echo 4 >&2; echo 5 >&2; echo 6"
+			args := append([]interface{}{"-c", script, WithCombinedPipe(), WithStreaming()}, tc.args...)
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			stdout := []string{}
+			for event := range events {
+				stdout = append(stdout, event.Data().Stdout()...)
+				validateResult(tt, 0, len(event.Data().Stderr()))
+			}
+			state := <-cmd.Wait()
+			validateResult(tt, tc.stdout, stdout)
+			validateResult(tt, int64(6), state.Stats().StdoutLines)
+		})
+	}
+}
@@ -6,8 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 // Data defines an interface for reading stdout and sterr.
@@ -21,6 +25,33 @@ type Data interface {
 
 	// Out returns a combined output of stdout and stderr
 	Out() []string
+
+	// StdoutLines returns the number of stdout lines
+	StdoutLines() int
+
+	// StderrLines returns the number of stderr lines
+	StderrLines() int
+
+	// StdoutBytes returns the size of the stdout lines in bytes, excluding
+	// line terminators
+	StdoutBytes() int
+
+	// StderrBytes returns the size of the stderr lines in bytes, excluding
+	// line terminators
+	StderrBytes() int
+
+	// Bytes returns the raw bytes of a chunk event (see WithChunkStreaming)
+	// and nil otherwise
+	Bytes() []byte
+
+	// Partial reports whether the event holds a segment of a line which
+	// continues in the next event of the same stream (see
+	// WithUnboundedLines)
+	Partial() bool
+
+	// Progress reports whether the line was terminated by a carriage return,
+	// i.e. is a redraw of a progress display (see WithProgressLines)
+	Progress() bool
 }
 
 // State defines an interface to read the final command state.
@@ -32,16 +63,105 @@ type State interface {
 
 	// Error returns the final error if any.
 	Error() error
+
+	// Stats returns output statistics of the command execution.
+	Stats() Stats
+
+	// Fingerprint returns the binary and environment fingerprint captured at
+	// start. It is nil unless WithFingerprint is set.
+	Fingerprint() *Fingerprint
+
+	// Labels returns the labels of the command (see WithContextLabels).
+	Labels() map[string]string
+
+	// IO returns the I/O counters of the process read at exit. It is only
+	// set if WithIOAccounting is used.
+	IO() IOStats
+
+	// Pid returns the process ID of the command. It is 0 if the process has
+	// not been started.
+	Pid() int
+
+	// StartTime returns the time the process was started.
+	StartTime() time.Time
+
+	// EndTime returns the time the process exited.
+	EndTime() time.Time
+
+	// Duration returns the run time of the process.
+	Duration() time.Duration
+
+	// Signaled reports whether the process was terminated by a signal.
+	Signaled() bool
+
+	// Signal returns the signal which terminated the process. It is only
+	// meaningful if Signaled returns true.
+	Signal() syscall.Signal
+
+	// ErrorClass returns the class of the failure recognized on stderr if
+	// WithErrorClassification is set and the process exited non-zero.
+	ErrorClass() ErrorClass
+
+	// ReadErrors returns the errors which occurred while reading stdout or
+	// stderr (see ReadError). They are reported separately from Error.
+	ReadErrors() []error
+
+	// DaemonPid returns the process ID read from the pid file of
+	// WithDaemonDetection, or 0.
+	DaemonPid() int
+
+	// Spilled returns the files holding the output which exceeded the
+	// threshold of WithSpill, or nil.
+	Spilled() (stdout, stderr *SpillFile)
+
+	// CancelReason returns why the command was cancelled: the reason given
+	// to CancelWithReason, ReasonDeadline, ReasonCanceled or an empty string
+	// if it was not cancelled.
+	CancelReason() string
 }
 
 // commandState represents the final state of a command execution.
 type commandState struct {
-	exit int
-	err  error
+	exit        int
+	err         error
+	stats       Stats
+	fingerprint *Fingerprint
+	labels      map[string]string
+	io          IOStats
+	reason      string
+	pid         int
+	start       time.Time
+	end         time.Time
+	signaled    bool
+	signal      syscall.Signal
+	class       ErrorClass
+	readErrors  []error
+	daemonPid   int
+	spillStdout *SpillFile
+	spillStderr *SpillFile
 }
 
 func (c *commandState) ExitCode() int { return c.exit }
 func (c *commandState) Error() error  { return c.err }
+func (c *commandState) Stats() Stats  { return c.stats }
+
+func (c *commandState) Fingerprint() *Fingerprint { return c.fingerprint }
+func (c *commandState) Labels() map[string]string { return c.labels }
+func (c *commandState) IO() IOStats               { return c.io }
+func (c *commandState) CancelReason() string      { return c.reason }
+func (c *commandState) Pid() int                  { return c.pid }
+func (c *commandState) StartTime() time.Time      { return c.start }
+func (c *commandState) EndTime() time.Time        { return c.end }
+func (c *commandState) Duration() time.Duration   { return c.end.Sub(c.start) }
+func (c *commandState) Signaled() bool            { return c.signaled }
+func (c *commandState) Signal() syscall.Signal    { return c.signal }
+func (c *commandState) ErrorClass() ErrorClass    { return c.class }
+func (c *commandState) ReadErrors() []error       { return c.readErrors }
+func (c *commandState) DaemonPid() int            { return c.daemonPid }
+
+func (c *commandState) Spilled() (stdout, stderr *SpillFile) {
+	return c.spillStdout, c.spillStderr
+}
 
 type commandResult struct {
 	stdout []string
@@ -63,9 +183,32 @@ func (r *commandResult) Out() []string {
 	return streams
 }
 
+func (r *commandResult) StdoutLines() int { return len(r.stdout) }
+func (r *commandResult) StderrLines() int { return len(r.stderr) }
+func (r *commandResult) StdoutBytes() int { return byteCount(r.stdout) }
+func (r *commandResult) StderrBytes() int { return byteCount(r.stderr) }
+func (r *commandResult) Bytes() []byte    { return nil }
+func (r *commandResult) Partial() bool    { return false }
+func (r *commandResult) Progress() bool   { return false }
+
+// byteCount returns the total length of lines.
+func byteCount(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		n += len(line)
+	}
+	return n
+}
+
 type streamData struct {
 	data     string
+	chunk    []byte
+	match    *Match
+	report   *ProgressEvent
+	partial  bool
+	progress bool
 	isStderr bool
+	at       time.Time
 	err      error
 }
 
@@ -91,6 +234,14 @@ func (s *streamData) Out() []string {
 	return []string{s.data}
 }
 
+func (s *streamData) StdoutLines() int { return len(s.Stdout()) }
+func (s *streamData) StderrLines() int { return len(s.Stderr()) }
+func (s *streamData) StdoutBytes() int { return byteCount(s.Stdout()) }
+func (s *streamData) StderrBytes() int { return byteCount(s.Stderr()) }
+func (s *streamData) Bytes() []byte    { return s.chunk }
+func (s *streamData) Partial() bool    { return s.partial }
+func (s *streamData) Progress() bool   { return s.progress }
+
 func newCommandResult(stdout, stderr []string) *commandResult {
 	r := &commandResult{
 		stdout: stdout,
@@ -103,11 +254,62 @@ func newCommandResult(stdout, stderr []string) *commandResult {
 type Event interface {
 	Error() error
 	Data() Data
+
+	// Labels returns the labels of the command (see WithContextLabels).
+	Labels() map[string]string
+
+	// Pid returns the process ID of the command which emitted the event.
+	Pid() int
+
+	// Seq returns the sequence number of the event. The events of a command
+	// are numbered in the order they were created, starting at 1; events
+	// which were combined, e.g. by WithBatching, leave gaps.
+	Seq() uint64
+
+	// Time returns the time the output of the event was read, or the time
+	// the event was created if it carries no output.
+	Time() time.Time
+
+	// Kind returns the kind of the event.
+	Kind() EventKind
+
+	// State returns the final state carried by an EventExited event (see
+	// WithLifecycleEvents), or nil.
+	State() State
+
+	// CommandID returns the ID of the command which emitted the event (see
+	// WithID).
+	CommandID() string
+
+	// CommandName returns the name of the command which emitted the event.
+	CommandName() string
+
+	// Stream returns the stream label of an output event, StreamStdout or
+	// StreamStderr, and an empty string for all other events.
+	Stream() string
+
+	// Match returns the match carried by an EventMatch event (see
+	// WithMatcher), or nil.
+	Match() *Match
+
+	// Progress returns the progress carried by an EventProgress event (see
+	// WithProgressParsers), or nil.
+	Progress() *ProgressEvent
 }
 
 type commandEvent struct {
-	data Data
-	err  error
+	data   Data
+	err    error
+	labels map[string]string
+	pid    int
+	seq    uint64
+	time   time.Time
+	kind   EventKind
+	state  State
+	id     string
+	name   string
+	match  *Match
+	report *ProgressEvent
 }
 
 func newCommandEvent(data Data, err error) *commandEvent {
@@ -121,12 +323,25 @@ func (evt *commandEvent) Error() error {
 func (evt *commandEvent) Data() Data {
 	return evt.data
 }
+func (evt *commandEvent) Labels() map[string]string {
+	return evt.labels
+}
+func (evt *commandEvent) Pid() int {
+	return evt.pid
+}
+func (evt *commandEvent) Seq() uint64 {
+	return evt.seq
+}
+func (evt *commandEvent) Time() time.Time {
+	return evt.time
+}
 
 // Option type sets an internal option (possibly obsolote)
 type Option func(*Command) error
 
 // commandService defines an interface for command execution.
 type commandService interface {
+	StdinPipe() (io.WriteCloser, error)
 	StdoutPipe() (io.ReadCloser, error)
 	StderrPipe() (io.ReadCloser, error)
 	Wait() error
@@ -143,6 +358,9 @@ type processStateService struct {
 }
 
 func newProcessState(cmd commandService) processState {
+	if state, ok := cmd.(processState); ok {
+		return state
+	}
 	return &processStateService{cmd: cmd}
 }
 
@@ -156,6 +374,7 @@ func (p *processStateService) ExitCode() int {
 // It might be useful in scenarios like a back end service where you want to
 // execute workload concurrently.
 type Command struct {
+	id           string
 	name         string
 	args         []string
 	outEvents    <-chan Event
@@ -163,8 +382,82 @@ type Command struct {
 	cmd          commandService
 	readDone     chan struct{}
 	stream       bool
-	finalState   chan State
+	limiter      *lineLimiter
+	stats        statsRecorder
+	fingerprint  bool
+	fp           *Fingerprint
+	labels       map[string]string
+	sinks        *sinks
+	discard      bool
+	linePrefix   string
+	timestamps   func(time.Time) string
+	onStdout     func(string)
+	onStderr     func(string)
+	lineFilter   func(string, bool) bool
+	transforms   []func(string) string
+	matchers     []matcher
 	ctx          context.Context // nil means none
+
+	stderrFailure   bool
+	stderrThreshold int64
+	envFuncs        []func(context.Context) (map[string]string, error)
+	secrets         secretSet
+	ioAccounting    bool
+	configurers     []func(*exec.Cmd) error
+	readOnlyPaths   []string
+	maskedPaths     []string
+	mountNamespace  bool
+	cleanups        []func()
+	sampleEvery     int
+	env             []string
+	replaceEnv      bool
+	envConfigured   bool
+	mu              sync.Mutex
+	cancelReason    string
+	stdinLines      <-chan string
+	stopSignal      os.Signal
+	stopGrace       time.Duration
+	processGroup    bool
+	progressParsers []ProgressParser
+	recordStart     func(string) bool
+	recordFlush     time.Duration
+	startTime       time.Time
+	errOnExit       bool
+	successCodes    []int
+	classifier      *errorClassifier
+	lookPath        bool
+	resolvedPath    string
+	customizers     []func(*exec.Cmd) error
+	events          <-chan Event
+	options         []OptionInfo
+	cancelFunc      func(*os.Process) error
+	waitDelay       time.Duration
+	processDone     chan struct{}
+	fairQuota       int
+	closeOnce       sync.Once
+	daemon          *daemonWatcher
+	chunkSize       int
+	maxLineSize     int
+	segmentSize     int
+	split           bufio.SplitFunc
+	progressLines   bool
+	nulDelimited    bool
+	encoding        Encoding
+	utf8Policy      InvalidUTF8Policy
+	eventBuffer     int
+	overflow        OverflowPolicy
+	forwarded       chan struct{}
+	batchLines      int
+	batchInterval   time.Duration
+	outputCap       *outputCap
+	spill           *spiller
+	combinedPipe    bool
+	seq             atomic.Uint64
+	lifecycle       bool
+	readErrors      []error
+	rawArgs         []interface{}
+	exited          chan struct{}
+	exitState       State
 }
 
 // NewCommand returns a new Command object. ctx must be a valid context.Context
@@ -177,12 +470,15 @@ func NewCommand(ctx context.Context, name string, args ...interface{}) (*Command
 	}
 
 	cmd := &Command{
-		name:       name,
-		ctx:        ctx,
-		finalState: make(chan State),
-		readDone:   make(chan struct{}),
-
-		args: make([]string, 0),
+		id:          fmt.Sprintf("cmd-%d", commandIDs.Add(1)),
+		name:        name,
+		ctx:         ctx,
+		readDone:    make(chan struct{}),
+		processDone: make(chan struct{}),
+		exited:      make(chan struct{}),
+
+		args:    make([]string, 0),
+		rawArgs: args,
 	}
 	userOpts := make([]Option, 0)
 	var err error
@@ -200,8 +496,19 @@ func NewCommand(ctx context.Context, name string, args ...interface{}) (*Command
 			return nil, err
 		}
 	}
+	if cmd.lookPath {
+		if err := cmd.resolve(); err != nil {
+			return nil, err
+		}
+	}
 	if cmd.cmd == nil {
-		cmd.cmd = exec.CommandContext(cmd.ctx, cmd.name, cmd.args...)
+		execCmd := cmd.newExecCmd()
+		for _, configure := range append(cmd.configurers, cmd.customizers...) {
+			if err := configure(execCmd); err != nil {
+				return nil, err
+			}
+		}
+		cmd.cmd = execCmd
 	}
 	cmd.processState = newProcessState(cmd.cmd)
 	return cmd, nil
@@ -218,6 +525,7 @@ func NewCommandStream(ctx context.Context, name string, args ...interface{}) (*C
 func WithStreaming() Option {
 
 	return func(c *Command) error {
+		c.record("WithStreaming")
 		c.stream = true
 		return nil
 	}
@@ -230,9 +538,27 @@ func withCommandService(v commandService) Option {
 	}
 }
 
-func readStream(ctx context.Context, inStream io.Reader, errStream bool) <-chan streamData {
+// newScanner returns the scanner for the output stream r.
+func (c *Command) newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	if c.maxLineSize > 0 {
+		// one more byte for the line terminator
+		size := c.maxLineSize + 1
+		if size > bufio.MaxScanTokenSize {
+			size = bufio.MaxScanTokenSize
+		}
+		scanner.Buffer(make([]byte, 0, size), c.maxLineSize+1)
+	}
+	if c.split != nil {
+		scanner.Split(c.split)
+	}
+	return scanner
+}
+
+// readStream emits the tokens of scanner. Scanner errors, e.g. lines longer
+// than the maximum line size, end the stream with a ReadError.
+func readStream(ctx context.Context, scanner *bufio.Scanner, errStream bool) <-chan streamData {
 	outStream := make(chan streamData)
-	scanner := bufio.NewScanner(inStream)
 	var event streamData
 
 	go func() {
@@ -241,6 +567,7 @@ func readStream(ctx context.Context, inStream io.Reader, errStream bool) <-chan
 		for scanner.Scan() {
 			text := scanner.Text()
 			event = *newStreamData(text, errStream)
+			event.at = time.Now()
 			select {
 			case <-ctx.Done():
 				break ForLoop
@@ -249,7 +576,7 @@ func readStream(ctx context.Context, inStream io.Reader, errStream bool) <-chan
 		}
 		if err := scanner.Err(); err != nil {
 			event = *newStreamData("", errStream)
-			event.err = err
+			event.err = newReadError(errStream, err)
 			select {
 			case <-ctx.Done():
 				return
@@ -260,42 +587,101 @@ func readStream(ctx context.Context, inStream io.Reader, errStream bool) <-chan
 	return outStream
 }
 
-func (c *Command) wait() <-chan State {
+func (c *Command) wait() {
 	go func() {
 		<-c.readDone
+		if c.forwarded != nil {
+			<-c.forwarded
+		}
+		ioStats := c.finalIO()
 		err := c.cmd.Wait()
-		state := &commandState{err: err}
+		end := time.Now()
+		c.cleanup()
+		state := &commandState{err: err, stats: c.stats.snapshot(), fingerprint: c.fp, labels: c.labels, io: ioStats, reason: c.reason(), pid: c.Pid(), start: c.startTime, end: end, readErrors: c.readErrorList()}
+		state.spillStdout, state.spillStderr = c.spill.files()
 		if err != nil {
 			state.exit = c.processState.ExitCode()
+			state.signaled, state.signal = c.waitSignal()
+			state.class = c.classifier.result()
+			state.err = waitError(err, state.reason)
+			if c.errOnExit {
+				if c.successCode(state.exit) {
+					state.err = nil
+				} else {
+					state.err = &ExitCodeError{Code: state.exit, Err: state.err}
+				}
+			}
+		} else {
+			state.err = c.checkStderr(state.stats)
 		}
-		c.finalState <- state
-
-		defer close(c.finalState)
+		if c.daemon != nil {
+			var daemonErr error
+			state.daemonPid, daemonErr = c.daemon.result(err != nil)
+			if state.err == nil {
+				state.err = daemonErr
+			}
+		}
+		c.exitState = state
+		close(c.exited)
 	}()
-	return c.finalState
 }
 
 func (c *Command) merge(ctx context.Context, channels ...<-chan streamData) <-chan Event {
 	var wg sync.WaitGroup
-	mergedStream := make(chan Event)
-
-	multiplex := func(c <-chan streamData) {
+	mergedStream := make(chan Event, c.eventBuffer)
+
+	emit := func(i streamData) bool {
+		var data Data = &i
+		switch {
+		case i.match != nil || i.report != nil:
+			data = newCommandResult([]string{}, []string{})
+		case i.chunk == nil:
+			line := newStreamData(c.secrets.redact(i.data), i.isStderr)
+			line.partial = i.partial
+			line.progress = i.progress
+			data = line
+		}
+		event := c.newEvent(data, i.err)
+		if i.match != nil {
+			event.kind = EventMatch
+			event.match = i.match
+		} else if i.report != nil {
+			event.kind = EventProgress
+			event.report = i.report
+		}
+		if !i.at.IsZero() {
+			event.time = i.at
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case mergedStream <- event:
+			return true
+		}
+	}
+	multiplex := func(ch <-chan streamData) {
 		defer wg.Done()
-		var event *commandEvent
-		for i := range c {
-			event = newCommandEvent(newStreamData(i.data, i.isStderr), nil)
-			select {
-			case <-ctx.Done():
+		f := c.newForwarder(emit)
+		for i := range ch {
+			if !f.forward(i) {
 				return
-			case mergedStream <- event:
 			}
 		}
+		f.finish()
 	}
 
-	// merge each channel
-	wg.Add(len(channels))
-	for _, c := range channels {
-		go multiplex(c)
+	if c.fairQuota > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.fairMerge(channels, emit)
+		}()
+	} else {
+		// merge each channel
+		wg.Add(len(channels))
+		for _, ch := range channels {
+			go multiplex(ch)
+		}
 	}
 
 	// Wait for all the reads to complete
@@ -312,26 +698,123 @@ func (c *Command) merge(ctx context.Context, channels ...<-chan streamData) <-ch
 }
 
 func (c *Command) start() (<-chan Event, error) {
+	if err := c.cancelError(); err != nil {
+		return nil, err
+	}
+	if err := c.acquireBudget(); err != nil {
+		return nil, err
+	}
+	if err := c.applyEnv(); err != nil {
+		return nil, err
+	}
 	stdoutPipe, err := c.cmd.StdoutPipe()
 	if err != nil {
-		return nil, err
+		return nil, withKind(err, ErrPipe)
 	}
-	stderrPipe, err := c.cmd.StderrPipe()
+	stderrPipe, err := c.stderrPipe()
 	if err != nil {
-		return nil, err
+		return nil, withKind(err, ErrPipe)
+	}
+	var stdinPipe io.WriteCloser
+	if c.stdinLines != nil {
+		if stdinPipe, err = c.cmd.StdinPipe(); err != nil {
+			return nil, withKind(err, ErrPipe)
+		}
 	}
-	if err := c.cmd.Start(); err != nil {
+	if c.fingerprint {
+		c.fp = c.captureFingerprint()
+	}
+	c.startTime = time.Now()
+	c.stats.started(c.startTime)
+	if err := c.startProcess(); err != nil {
 		return nil, err
 	}
-	c.outEvents = c.merge(c.ctx, readStream(c.ctx, stdoutPipe, false), readStream(c.ctx, stderrPipe, true))
+	c.watchExit(stdoutPipe, stderrPipe)
+	if c.daemon != nil {
+		c.watchDaemon(stdoutPipe, stderrPipe)
+	}
+	if stdinPipe != nil {
+		go c.feedLines(stdinPipe)
+	}
+	var stdout, stderr io.Reader
+	stdout = &countingReader{r: stdoutPipe, rec: &c.stats}
+	stderr = &countingReader{r: stderrPipe, rec: &c.stats, isStderr: true}
+	if c.encoding != nil {
+		stdout, stderr = c.encoding.Decode(stdout), c.encoding.Decode(stderr)
+	}
+	switch {
+	case c.chunkSize > 0:
+		c.outEvents = c.merge(c.ctx, readChunks(c.ctx, stdout, false, c.chunkSize), readChunks(c.ctx, stderr, true, c.chunkSize))
+	case c.segmentSize > 0:
+		segment := c.segmentSize
+		if !c.stream {
+			segment = 0
+		}
+		c.outEvents = c.merge(c.ctx, readLines(c.ctx, stdout, false, segment), readLines(c.ctx, stderr, true, segment))
+	default:
+		c.outEvents = c.merge(c.ctx, readStream(c.ctx, c.newScanner(stdout), false), readStream(c.ctx, c.newScanner(stderr), true))
+	}
 	return c.outEvents, nil
 }
 
-// Wait must be called after Execute to complete command execution and to
-// cleanup resources. It returns a channel which you are required to read from
-// to complete the process.
+// cleanup releases resources which are bound to a single execution.
+func (c *Command) cleanup() {
+	for _, fn := range c.cleanups {
+		fn()
+	}
+	c.cleanups = nil
+}
+
+// Pid returns the process ID of the command. It is 0 if the process has not
+// been started.
+func (c *Command) Pid() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cmd, ok := c.cmd.(*exec.Cmd); ok && cmd.Process != nil {
+		return cmd.Process.Pid
+	}
+	return 0
+}
+
+// waitSignal returns the signal which terminated the process, if any.
+func (c *Command) waitSignal() (bool, syscall.Signal) {
+	cmd, ok := c.cmd.(*exec.Cmd)
+	if !ok || cmd.ProcessState == nil {
+		return false, 0
+	}
+	if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return true, status.Signal()
+	}
+	return false, 0
+}
+
+// Wait returns a channel which delivers the final state once the command
+// completed. Every call returns a new channel, so the final state can be read
+// by multiple goroutines and after completion. See also WaitContext.
 func (c *Command) Wait() <-chan State {
-	return c.finalState
+	ch := make(chan State, 1)
+	go func() {
+		<-c.exited
+		ch <- c.exitState
+		close(ch)
+	}()
+	return ch
+}
+
+// WaitContext blocks until the command completed and returns its final
+// state, or ctx's error if ctx is done first. Like Wait, it can be called
+// any number of times. It returns ErrNotStarted if Execute has not been
+// called.
+func (c *Command) WaitContext(ctx context.Context) (State, error) {
+	if c.events == nil {
+		return nil, ErrNotStarted
+	}
+	select {
+	case <-c.exited:
+		return c.exitState, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // Execute starts the command execution. You are required to read from the event
@@ -342,26 +825,87 @@ func (c *Command) Wait() <-chan State {
 func (c *Command) Execute() (<-chan Event, error) {
 	var event *commandEvent
 	var stdout, stderr []string
-	outStream := make(chan Event)
+	outStream := make(chan Event, c.eventBuffer)
+	if (c.stream && c.overflow != OverflowBlock) || c.outputCap != nil || c.spill != nil || c.sinks != nil ||
+		c.onStdout != nil || c.onStderr != nil {
+		// the final state waits for the dropped lines to be counted, the
+		// writers to be closed and the line callbacks to return
+		c.forwarded = make(chan struct{})
+	}
 
 	inStream, err := c.start()
 	if err != nil {
+		c.cleanup()
 		return nil, err
 	}
+	if c.stream && c.recordStart != nil {
+		inStream = c.recordEvents(inStream)
+	} else if c.stream && c.batchLines > 0 {
+		inStream = c.batchEvents(inStream)
+	}
+	send := func(evt Event) bool {
+		select {
+		case <-c.ctx.Done():
+			return false
+		case outStream <- evt:
+			return true
+		}
+	}
 	resultReader := func() {
 		stdout = []string{}
 		stderr = []string{}
+		if c.spill != nil {
+			c.spill.delim = c.delimiter()
+		}
+		if c.lifecycle {
+			send(c.lifecycleEvent(EventStarted, nil))
+		}
 	ForLoop:
 		for v := range inStream {
+			if (len(c.matchers) > 0 || len(c.progressParsers) > 0) && (v.Kind() == EventMatch || v.Kind() == EventProgress) {
+				// matches and progress bypass the output handling
+				if !send(v) {
+					break ForLoop
+				}
+				continue
+			}
+			if c.outputCap != nil && v.Error() == nil {
+				if ok, first := c.outputCap.admit(v.Data()); !ok {
+					c.stats.addTruncated(v.Data())
+					if first && c.stream && !send(c.newEvent(newStreamData("", false), ErrOutputTruncated)) {
+						break ForLoop
+					}
+					continue
+				}
+			}
+			if (c.onStdout != nil || c.onStderr != nil) && v.Error() == nil {
+				c.callLineCallbacks(v.Data())
+			}
+			if c.sinks != nil && v.Error() == nil && !c.sinks.write(v) {
+				continue
+			}
 			if c.stream {
-
-				select {
-				case <-c.ctx.Done():
+				if c.limiter != nil && v.Error() == nil {
+					if !c.limiter.allow(time.Now()) {
+						continue
+					}
+					if err := c.limiter.flush(); err != nil && !send(c.newEvent(newStreamData("", false), err)) {
+						break ForLoop
+					}
+				}
+				if c.overflow != OverflowBlock && v.Error() == nil {
+					if !c.offer(c.ctx, outStream, v) {
+						break ForLoop
+					}
+				} else if !send(v) {
 					break ForLoop
-
-				case outStream <- v:
 				}
-			} else {
+			} else if v.Error() == nil && !c.discard {
+				if c.spill != nil {
+					c.spill.add(v.Data().Stderr(), true, &stderr)
+					c.spill.add(v.Data().Stdout(), false, &stdout)
+					continue
+				}
 				if len(v.Data().Stderr()) > 0 {
 					for _, i := range v.Data().Stderr() {
 						stderr = append(stderr, i)
@@ -374,19 +918,67 @@ func (c *Command) Execute() (<-chan Event, error) {
 				}
 			}
 		}
-		if !c.stream {
-			event = newCommandEvent(newCommandResult(stdout, stderr), errors.New("no error"))
-			select {
-			case <-c.ctx.Done():
-				outStream <- event
-				return
-			case outStream <- event:
+		if c.spill != nil {
+			c.spill.finish(c.ctx)
+		}
+		if c.sinks != nil {
+			c.sinks.finish()
+		}
+		if c.forwarded != nil {
+			close(c.forwarded)
+		}
+		if c.stream {
+			if c.limiter != nil {
+				if err := c.limiter.flush(); err != nil {
+					send(c.newEvent(newStreamData("", false), err))
+				}
 			}
+			if c.sinks != nil && c.sinks.err != nil {
+				send(c.newEvent(newStreamData("", false), c.sinks.err))
+			}
+			if err := c.cancelError(); err != nil {
+				send(c.newEvent(newStreamData("", false), err))
+			} else if c.errOnExit {
+				if err := c.exitCodeError(); err != nil {
+					send(c.newEvent(newStreamData("", false), err))
+				}
+			}
+		} else {
+			err := c.cancelError()
+			if err == nil && c.errOnExit {
+				err = c.exitCodeError()
+			}
+			if err == nil && c.outputCap != nil && c.outputCap.truncated {
+				stats := c.stats.snapshot()
+				err = fmt.Errorf("%w: %d lines (%d bytes) dropped", ErrOutputTruncated, stats.TruncatedLines, stats.TruncatedBytes)
+			}
+			if err == nil && c.spill != nil {
+				err = c.spill.err
+			}
+			if err == nil && c.sinks != nil {
+				err = c.sinks.err
+			}
+			if err == nil && !c.lifecycle {
+				err = errors.New("no error")
+			}
+			event = c.newEvent(newCommandResult(stdout, stderr), err)
+			event.kind = EventResult
+			// the result is delivered even if the context is done
+			outStream <- event
+		}
+		if c.lifecycle {
+			<-c.exited
+			outStream <- c.lifecycleEvent(EventExited, c.exitState)
 		}
 		close(outStream)
 	}
 
 	go resultReader()
 
+	if c.discard {
+		c.events = discardEvents(outStream)
+		return c.events, nil
+	}
+	c.events = outStream
 	return outStream, nil
 }
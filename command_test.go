@@ -4,6 +4,7 @@
 package command
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"io"
@@ -109,7 +110,7 @@ func TestCommandReadStream(t *testing.T) {
 		{
 			name:   "readError",
 			args:   testData{reader: &ReaderErrorMock{err: errors.New("errRead")}},
-			expect: testData{err: errors.New("errRead"), result: []string{"test"}},
+			expect: testData{err: errors.New("read stdout: errRead"), result: []string{"test"}},
 		},
 		{
 			name:     "readErrorCancel",
@@ -135,7 +136,7 @@ func TestCommandReadStream(t *testing.T) {
 				}
 			}
 			tc.got = testData{result: make([]string, 0)}
-			tc.got.stream = readStream(ctx, tc.args.reader, tc.args.isErrStream)
+			tc.got.stream = readStream(ctx, bufio.NewScanner(tc.args.reader), tc.args.isErrStream)
 
 			var ok bool
 			var data streamData
@@ -472,6 +473,25 @@ func TestStreamData(t *testing.T) {
 
 }
 
+func TestDataCounts(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   Data
+		expect [4]int
+	}{
+		{name: "result", data: newCommandResult([]string{"ab", "", "cde"}, []string{"error"}), expect: [4]int{3, 1, 5, 5}},
+		{name: "resultEmpty", data: newCommandResult([]string{}, nil), expect: [4]int{0, 0, 0, 0}},
+		{name: "streamStdout", data: newStreamData("stdout", false), expect: [4]int{1, 0, 6, 0}},
+		{name: "streamStderr", data: newStreamData("err", true), expect: [4]int{0, 1, 0, 3}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			got := [4]int{tc.data.StdoutLines(), tc.data.StderrLines(), tc.data.StdoutBytes(), tc.data.StderrBytes()}
+			validateResult(tt, tc.expect, got)
+		})
+	}
+}
+
 type TestCaseCommandEvent struct {
 	name         string
 	commandEvent *commandEvent
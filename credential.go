@@ -0,0 +1,20 @@
+package command
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// WithCredential runs the command as user uid and group gid with the
+// supplementary groups. The caller needs the privileges to switch to them.
+// It is supported on Unix only; NewCommand fails on Windows.
+func WithCredential(uid, gid uint32, groups []uint32) Option {
+
+	return func(c *Command) error {
+		c.record("WithCredential", fmt.Sprintf("uid=%d gid=%d groups=%v", uid, gid, groups))
+		c.configurers = append(c.configurers, func(cmd *exec.Cmd) error {
+			return setCredential(cmd, uid, gid, groups)
+		})
+		return nil
+	}
+}
@@ -0,0 +1,24 @@
+// +build !integration
+// +build unit
+// +build !windows
+
+package command
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestCommandCredential(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("switching credentials requires root")
+	}
+	cmd, err := NewCommand(context.Background(), "sh", "-c", "id -u; id -g; id -G", WithCredential(65534, 65533, []uint32{65532}))
+	validateError(t, nil, err)
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	event := <-events
+	validateResult(t, 0, (<-cmd.Wait()).ExitCode())
+	validateResult(t, []string{"65534", "65533", "65533 65532"}, event.Data().Stdout())
+}
@@ -0,0 +1,13 @@
+// +build !windows
+
+package command
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func setCredential(cmd *exec.Cmd, uid, gid uint32, groups []uint32) error {
+	sysProcAttr(cmd).Credential = &syscall.Credential{Uid: uid, Gid: gid, Groups: groups}
+	return nil
+}
@@ -0,0 +1,10 @@
+package command
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func setCredential(cmd *exec.Cmd, uid, gid uint32, groups []uint32) error {
+	return fmt.Errorf("credential: %w", ErrUnsupported)
+}
@@ -0,0 +1,17 @@
+package command
+
+import "os/exec"
+
+// WithCmdCustomizer registers fn to tune the underlying exec.Cmd for fields
+// this package does not model. Customizers run in order after the exec.Cmd
+// has been built and all other options have been applied to it; an error
+// aborts NewCommand. Note that options like WithReadOnlyPaths replace the
+// executable with a wrapper shell before customizers run.
+func WithCmdCustomizer(fn func(*exec.Cmd) error) Option {
+
+	return func(c *Command) error {
+		c.record("WithCmdCustomizer", funcValue)
+		c.customizers = append(c.customizers, fn)
+		return nil
+	}
+}
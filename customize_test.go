@@ -0,0 +1,60 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestCommandCmdCustomizer(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   []interface{}
+		expect []string
+		err    error
+	}{
+		{
+			name: "env",
+			opts: []interface{}{WithCmdCustomizer(func(cmd *exec.Cmd) error {
+				cmd.Env = append(os.Environ(), "CUSTOM=1")
+				return nil
+			})},
+			expect: []string{"1"},
+		},
+		{
+			name: "afterOptions",
+			opts: []interface{}{
+				WithCmdCustomizer(func(cmd *exec.Cmd) error {
+					cmd.Env = append(cmd.Env, "CUSTOM=2")
+					return nil
+				}),
+				WithEnv([]string{"CUSTOM=overridden"}),
+			},
+			expect: []string{"2"},
+		},
+		{
+			name: "error",
+			opts: []interface{}{WithCmdCustomizer(func(cmd *exec.Cmd) error { return errors.New("customizer failed") })},
+			err:  errors.New("customizer failed"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append([]interface{}{"-c", `echo "$CUSTOM"`}, tc.opts...)...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			event := <-events
+			<-cmd.Wait()
+			validateResult(tt, tc.expect, event.Data().Stdout())
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrDaemonNotReady is matched by State.Error if the daemon of a command
+// with WithDaemonDetection was not up within the timeout.
+var ErrDaemonNotReady = newCodedError("DAEMON_NOT_READY", "daemon not ready")
+
+// defaultDaemonTimeout is used if DaemonDetection.Timeout is zero.
+const defaultDaemonTimeout = 10 * time.Second
+
+// daemonPollInterval is the default interval the pid file is checked at.
+const daemonPollInterval = 50 * time.Millisecond
+
+// DaemonDetection describes how to recognize that the daemon started by a
+// command is up.
+type DaemonDetection struct {
+	// PidFile is the file the daemon writes its process ID to. The daemon
+	// is up once the file names a running process. Pid files are only
+	// supported on Unix.
+	PidFile string
+
+	// Ready is matched against the output lines of the command and the
+	// daemon. The daemon is up once a line matched.
+	Ready *regexp.Regexp
+
+	// Timeout bounds the time to wait for the daemon after the command
+	// exited, 10s if zero.
+	Timeout time.Duration
+}
+
+// WithDaemonDetection is meant for commands which fork a daemon and exit,
+// like nginx. Once the command exited successfully, the final state is
+// delayed until the daemon is up according to d and State.DaemonPid reports
+// the process ID read from the pid file. If the daemon is not up within the
+// timeout, State.Error matches ErrDaemonNotReady. On Linux, the output
+// pipes are closed shortly after the daemon is up, so that a daemon which
+// keeps the output of the command open doesn't block the execution. Output
+// which has not been read by then is lost.
+func WithDaemonDetection(d DaemonDetection) Option {
+
+	return func(c *Command) error {
+		c.record("WithDaemonDetection", d.PidFile, d.Ready, d.Timeout)
+		if d.PidFile == "" && d.Ready == nil {
+			return errors.New("daemon detection requires a pid file or a ready pattern")
+		}
+		if d.Timeout < 0 {
+			return fmt.Errorf("invalid daemon timeout: %v", d.Timeout)
+		}
+		if d.Timeout == 0 {
+			d.Timeout = defaultDaemonTimeout
+		}
+		c.daemon = &daemonWatcher{
+			DaemonDetection: d,
+			interval:        daemonPollInterval,
+			ready:           make(chan struct{}),
+			stop:            make(chan struct{}),
+			done:            make(chan struct{}),
+		}
+		if d.Ready == nil {
+			close(c.daemon.ready)
+		}
+		return nil
+	}
+}
+
+// daemonWatcher waits for the daemon of a command.
+type daemonWatcher struct {
+	DaemonDetection
+	interval time.Duration
+	once     sync.Once
+	ready    chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	mu       sync.Mutex
+	closed   bool
+	pid      int
+	err      error
+}
+
+// observe matches line against the ready pattern.
+func (d *daemonWatcher) observe(line string) {
+	if d.Ready != nil && d.Ready.MatchString(line) {
+		d.once.Do(func() { close(d.ready) })
+	}
+}
+
+// closedPipe reports whether err was caused by the watcher closing the
+// output pipes.
+func (d *daemonWatcher) closedPipe(err error) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed && errors.Is(err, os.ErrClosed)
+}
+
+// result returns the daemon's process ID and the detection error. If the
+// command failed, the daemon is not waited for.
+func (d *daemonWatcher) result(failed bool) (int, error) {
+	if failed {
+		close(d.stop)
+	}
+	<-d.done
+	return d.pid, d.err
+}
+
+// watchDaemon waits for the started process to exit and then for the
+// daemon to be up. The pipes are closed afterwards unless the output has
+// been read completely.
+func (c *Command) watchDaemon(pipes ...io.Closer) {
+	d := c.daemon
+	pid := c.Pid()
+	go func() {
+		defer close(d.done)
+		if waitExited(pid) != nil {
+			select {
+			case <-c.readDone:
+			case <-d.stop:
+				return
+			}
+		}
+		d.pid, d.err = d.wait(c.ctx.Done())
+		// give the readers a chance to consume the buffered output
+		timer := time.NewTimer(d.interval)
+		defer timer.Stop()
+		select {
+		case <-c.readDone:
+			return
+		case <-timer.C:
+		}
+		d.mu.Lock()
+		d.closed = true
+		d.mu.Unlock()
+		for _, p := range pipes {
+			p.Close()
+		}
+	}()
+}
+
+// wait polls until the daemon is up, the timeout elapsed, the command
+// failed or ctxDone is closed.
+func (d *daemonWatcher) wait(ctxDone <-chan struct{}) (int, error) {
+	timeout := time.NewTimer(d.Timeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	ready := d.ready
+	for {
+		pid, err := d.check()
+		if err == nil {
+			return pid, nil
+		}
+		select {
+		case <-d.stop:
+			return 0, nil
+		case <-ctxDone:
+			return 0, fmt.Errorf("%w: %v", ErrDaemonNotReady, err)
+		case <-timeout.C:
+			return 0, fmt.Errorf("%w after %v: %v", ErrDaemonNotReady, d.Timeout, err)
+		case <-ready:
+			ready = nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check returns the daemon's process ID if it is up.
+func (d *daemonWatcher) check() (int, error) {
+	select {
+	case <-d.ready:
+	default:
+		return 0, errors.New("ready pattern not matched")
+	}
+	if d.PidFile == "" {
+		return 0, nil
+	}
+	data, err := ioutil.ReadFile(d.PidFile)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid pid file %s", d.PidFile)
+	}
+	p, err := os.FindProcess(pid)
+	if err == nil {
+		err = p.Signal(syscall.Signal(0))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("process %d: %v", pid, err)
+	}
+	return pid, nil
+}
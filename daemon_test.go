@@ -0,0 +1,142 @@
+// +build !integration
+// +build unit
+// +build linux
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDaemonDetection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "daemon")
+	validateError(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		name    string
+		script  string
+		pidFile bool
+		ready   string
+		timeout time.Duration
+		daemon  bool
+		stdout  []string
+		err     error
+		code    string
+		optErr  error
+	}{
+		{name: "pidFile", script: `echo start; sleep 10 & echo $! > "$PIDFILE"`, pidFile: true, daemon: true, stdout: []string{"start"}},
+		{name: "ready", script: "echo ready", ready: "^ready$", stdout: []string{"ready"}},
+		{
+			name:    "timeout",
+			script:  "echo start",
+			pidFile: true,
+			timeout: time.Millisecond,
+			stdout:  []string{"start"},
+			err:     ErrDaemonNotReady,
+			code:    "DAEMON_NOT_READY",
+		},
+		{name: "failed", script: "exit 3", pidFile: true, stdout: []string{}, err: ErrNonZeroExit, code: "NON_ZERO_EXIT"},
+		{name: "invalid", optErr: errors.New("daemon detection requires a pid file or a ready pattern")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			pidFile := filepath.Join(dir, tc.name+".pid")
+			d := DaemonDetection{Timeout: tc.timeout}
+			if tc.pidFile {
+				d.PidFile = pidFile
+			}
+			if tc.ready != "" {
+				d.Ready = regexp.MustCompile(tc.ready)
+			}
+			cmd, err := NewCommand(context.Background(), "sh", "-c", tc.script, WithEnv([]string{"PIDFILE=" + pidFile}), WithDaemonDetection(d))
+			validateError(tt, tc.optErr, err)
+			if err != nil {
+				return
+			}
+			cmd.daemon.interval = time.Millisecond
+			start := time.Now()
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			event := <-events
+			state := <-cmd.Wait()
+			validateBool(tt, true, time.Since(start) < 5*time.Second)
+			validateResult(tt, tc.stdout, event.Data().Stdout())
+			validateBool(tt, true, errors.Is(state.Error(), tc.err))
+			validateResult(tt, tc.code, ErrorCode(state.Error()))
+			validateResult(tt, 0, len(state.ReadErrors()))
+			validateBool(tt, tc.daemon, state.DaemonPid() != 0)
+			if state.DaemonPid() != 0 {
+				validateError(tt, nil, syscall.Kill(state.DaemonPid(), syscall.SIGKILL))
+			}
+		})
+	}
+}
+
+func TestDaemonWait(t *testing.T) {
+	dir, err := ioutil.TempDir("", "daemon")
+	validateError(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		name   string
+		d      DaemonDetection
+		signal func(d *daemonWatcher)
+		pid    int
+		err    error
+	}{
+		{
+			name:   "delayedPidFile",
+			d:      DaemonDetection{PidFile: filepath.Join(dir, "delayed.pid"), Timeout: time.Second},
+			signal: func(d *daemonWatcher) { ioutil.WriteFile(d.PidFile, []byte(fmt.Sprint(os.Getpid())), 0644) },
+			pid:    os.Getpid(),
+		},
+		{
+			name:   "delayedReady",
+			d:      DaemonDetection{Ready: regexp.MustCompile("^ready$"), Timeout: time.Second},
+			signal: func(d *daemonWatcher) { d.observe("ready") },
+		},
+		{
+			name:   "stopped",
+			d:      DaemonDetection{PidFile: filepath.Join(dir, "stopped.pid"), Timeout: time.Second},
+			signal: func(d *daemonWatcher) { close(d.stop) },
+		},
+		{
+			name: "timeout",
+			d:    DaemonDetection{Ready: regexp.MustCompile("^ready$"), Timeout: time.Millisecond},
+			err:  ErrDaemonNotReady,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", WithDaemonDetection(tc.d))
+			validateError(tt, nil, err)
+			d := cmd.daemon
+			d.interval = time.Millisecond
+			type result struct {
+				pid int
+				err error
+			}
+			done := make(chan result)
+			go func() {
+				pid, err := d.wait(nil)
+				done <- result{pid, err}
+			}()
+			if tc.signal != nil {
+				tc.signal(d)
+			}
+			r := <-done
+			validateResult(tt, tc.pid, r.pid)
+			validateBool(tt, true, errors.Is(r.err, tc.err))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// WithDeadlinePropagation passes the time remaining until the deadline of
+// the command's context to the process, so it can limit itself instead of
+// being killed. If flag starts with "-" it is appended as argument
+// "flag=value" (e.g. "--timeout=25s"), otherwise flag names an environment
+// variable which is set to the value. The value is computed by format when
+// the command is executed; a nil format truncates to whole seconds and uses
+// time.Duration.String. Nothing is passed if the context has no deadline.
+func WithDeadlinePropagation(flag string, format func(time.Duration) string) Option {
+
+	return func(c *Command) error {
+		c.record("WithDeadlinePropagation", flag)
+		if flag == "" || flag == "-" || flag == "--" {
+			return fmt.Errorf("invalid deadline flag: %q", flag)
+		}
+		if format == nil {
+			format = formatSeconds
+		}
+		c.envFuncs = append(c.envFuncs, func(ctx context.Context) (map[string]string, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				return nil, nil
+			}
+			remaining := time.Until(deadline)
+			if remaining < 0 {
+				remaining = 0
+			}
+			value := format(remaining)
+			if !strings.HasPrefix(flag, "-") {
+				return map[string]string{flag: value}, nil
+			}
+			if cmd, ok := c.cmd.(*exec.Cmd); ok {
+				cmd.Args = append(cmd.Args, flag+"="+value)
+			}
+			return nil, nil
+		})
+		return nil
+	}
+}
+
+func formatSeconds(d time.Duration) string {
+	return d.Truncate(time.Second).String()
+}
@@ -0,0 +1,55 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCommandDeadlinePropagation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		timeout time.Duration
+		flag    string
+		format  func(time.Duration) string
+		expect  []string
+		err     error
+	}{
+		{name: "arg", timeout: time.Minute, flag: "--timeout", expect: []string{"--timeout=59s", ""}},
+		{name: "env", timeout: time.Minute, flag: "TIMEOUT", expect: []string{"", "59s"}},
+		{
+			name:    "format",
+			timeout: time.Minute,
+			flag:    "-t",
+			format:  func(d time.Duration) string { return fmt.Sprint(int(d.Minutes() + 0.5)) },
+			expect:  []string{"-t=1", ""},
+		},
+		{name: "noDeadline", flag: "--timeout", expect: []string{"", ""}},
+		{name: "invalid", flag: "--", err: errors.New(`invalid deadline flag: "--"`)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+			cmd, err := NewCommand(ctx, "sh", "-c", `echo "$1"; echo "$TIMEOUT"`, "sh", WithDeadlinePropagation(tc.flag, tc.format))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			event := <-events
+			<-cmd.Wait()
+			validateResult(tt, tc.expect, event.Data().Stdout())
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrNoYAML is returned by Result.DecodeYAML if no YAML unmarshaler has been
+// registered with RegisterYAML.
+var ErrNoYAML = newCodedError("NO_YAML", "no yaml unmarshaler registered")
+
+// UnmarshalFunc decodes data into v, like json.Unmarshal.
+type UnmarshalFunc func(data []byte, v interface{}) error
+
+var yamlUnmarshal struct {
+	sync.RWMutex
+	fn UnmarshalFunc
+}
+
+// RegisterYAML makes a YAML unmarshaler like yaml.Unmarshal of
+// gopkg.in/yaml.v3 available to Result.DecodeYAML, so that the package
+// doesn't depend on a YAML library. It panics if fn is nil or an
+// unmarshaler is already registered.
+func RegisterYAML(fn UnmarshalFunc) {
+	yamlUnmarshal.Lock()
+	defer yamlUnmarshal.Unlock()
+	if fn == nil {
+		panic("command: invalid yaml registration")
+	}
+	if yamlUnmarshal.fn != nil {
+		panic("command: yaml registered twice")
+	}
+	yamlUnmarshal.fn = fn
+}
+
+// Decode decodes the collected stdout into v using unmarshal. Leading and
+// trailing lines for which skip returns true are left out, e.g. banners or
+// deprecation notices around the document; skip may be nil. The error of
+// unmarshal is returned as *DecodeError with an empty Line. If the command
+// could not be executed, Err is returned.
+func (r *Result) Decode(v interface{}, unmarshal UnmarshalFunc, skip func(line string) bool) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	if r.Data == nil {
+		return &DecodeError{Err: errors.New("no output")}
+	}
+	lines := r.Data.Stdout()
+	if skip != nil {
+		for len(lines) > 0 && skip(lines[0]) {
+			lines = lines[1:]
+		}
+		for len(lines) > 0 && skip(lines[len(lines)-1]) {
+			lines = lines[:len(lines)-1]
+		}
+	}
+	if err := unmarshal([]byte(strings.Join(lines, "\n")), v); err != nil {
+		return &DecodeError{Err: err}
+	}
+	return nil
+}
+
+// DecodeJSON decodes the collected stdout as a JSON document into v (see
+// Decode).
+func (r *Result) DecodeJSON(v interface{}, skip func(line string) bool) error {
+	return r.Decode(v, json.Unmarshal, skip)
+}
+
+// DecodeYAML decodes the collected stdout as a YAML document into v (see
+// Decode) using the unmarshaler registered with RegisterYAML. It returns
+// ErrNoYAML if there is none.
+func (r *Result) DecodeYAML(v interface{}, skip func(line string) bool) error {
+	yamlUnmarshal.RLock()
+	fn := yamlUnmarshal.fn
+	yamlUnmarshal.RUnlock()
+	if fn == nil {
+		return ErrNoYAML
+	}
+	return r.Decode(v, fn, skip)
+}
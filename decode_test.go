@@ -0,0 +1,99 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResultDecodeJSON(t *testing.T) {
+	notJSON := func(line string) bool {
+		return !strings.HasPrefix(line, "{") && !strings.HasPrefix(line, "}") && !strings.HasPrefix(line, " ")
+	}
+	testCases := []struct {
+		name   string
+		result *Result
+		skip   func(string) bool
+		value  map[string]interface{}
+		err    error
+	}{
+		{
+			name:   "document",
+			result: &Result{Data: newCommandResult([]string{"{", `  "name": "a"`, "}"}, []string{})},
+			value:  map[string]interface{}{"name": "a"},
+		},
+		{
+			name:   "noise",
+			result: &Result{Data: newCommandResult([]string{"Warning: deprecated", "{", `  "name": "a"`, "}", "done"}, []string{})},
+			skip:   notJSON,
+			value:  map[string]interface{}{"name": "a"},
+		},
+		{
+			name:   "noiseNotSkipped",
+			result: &Result{Data: newCommandResult([]string{"Warning: deprecated", "{}"}, []string{})},
+			err:    errors.New("decode: invalid character 'W' looking for beginning of value"),
+		},
+		{
+			name:   "noOutput",
+			result: &Result{},
+			err:    errors.New("decode: no output"),
+		},
+		{
+			name:   "notExecuted",
+			result: &Result{Err: errors.New("errStart")},
+			err:    errors.New("errStart"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			var value map[string]interface{}
+			err := tc.result.DecodeJSON(&value, tc.skip)
+			validateError(tt, tc.err, err)
+			validateResult(tt, tc.value, value)
+			if err != nil && tc.result.Err == nil {
+				validateBool(tt, true, errors.Is(err, ErrDecode))
+			}
+		})
+	}
+}
+
+func TestResultDecodeCommand(t *testing.T) {
+	cmd, err := NewCommand(context.Background(), "sh", withCommandService(&CommandServiceMock{stdout: "notice: cached\n{\"size\": 3}\n"}))
+	validateError(t, nil, err)
+	_, err = cmd.Execute()
+	validateError(t, nil, err)
+	result, _, err := cmd.Drain(context.Background())
+	validateError(t, nil, err)
+	var value struct{ Size int }
+	validateError(t, nil, result.DecodeJSON(&value, func(line string) bool {
+		return strings.HasPrefix(line, "notice:")
+	}))
+	validateResult(t, 3, value.Size)
+}
+
+func TestResultDecodeYAML(t *testing.T) {
+	result := &Result{Data: newCommandResult([]string{"name: a", "size: 3"}, []string{})}
+	var value map[string]string
+	validateError(t, ErrNoYAML, result.DecodeYAML(&value, nil))
+
+	// a minimal unmarshaler for flat mappings
+	RegisterYAML(func(data []byte, v interface{}) error {
+		m := map[string]string{}
+		for _, line := range strings.Split(string(data), "\n") {
+			kv := strings.SplitN(line, ": ", 2)
+			if len(kv) != 2 {
+				return errors.New("invalid mapping")
+			}
+			m[kv[0]] = kv[1]
+		}
+		*(v.(*map[string]string)) = m
+		return nil
+	})
+	defer func() { yamlUnmarshal.fn = nil }()
+	validateError(t, nil, result.DecodeYAML(&value, nil))
+	validateResult(t, map[string]string{"name": "a", "size": "3"}, value)
+}
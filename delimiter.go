@@ -0,0 +1,125 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// WithNulDelimited splits the output into records terminated by NUL bytes
+// instead of lines, as printed by find -print0 or git ls-files -z. The
+// delimiter is stripped, so records may contain newlines. It has no effect
+// with WithChunkStreaming or WithUnboundedLines.
+func WithNulDelimited() Option {
+
+	return func(c *Command) error {
+		c.record("WithNulDelimited")
+		c.split = scanNul
+		c.nulDelimited = true
+		c.progressLines = false
+		return nil
+	}
+}
+
+// delimiter returns the byte which terminates the records of the output.
+func (c *Command) delimiter() byte {
+	if c.nulDelimited {
+		return 0
+	}
+	return '\n'
+}
+
+// scanNul is a bufio.SplitFunc returning NUL-terminated records. The last
+// record doesn't need to be terminated.
+func scanNul(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// WithProgressLines treats a carriage return as line terminator, so that
+// progress displays which are redrawn with "\r", as printed by curl, rsync
+// or pip, are emitted as separate lines instead of being dropped or
+// accumulated into a single line. Such lines are marked by Data.Progress.
+// If coalesce is set, successive redraws which are read at once are
+// coalesced into the last one. It has no effect with WithChunkStreaming or
+// WithUnboundedLines.
+func WithProgressLines(coalesce bool) Option {
+
+	return func(c *Command) error {
+		c.record("WithProgressLines", coalesce)
+		c.split = scanProgress(coalesce)
+		c.nulDelimited = false
+		c.progressLines = true
+		return nil
+	}
+}
+
+// scanProgress returns a bufio.SplitFunc which splits lines at "\n", "\r\n"
+// and "\r". Tokens terminated by a single "\r" keep it, see progressLine.
+func scanProgress(coalesce bool) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		start := 0
+		for {
+			i := bytes.IndexAny(data[start:], "\r\n")
+			if i < 0 {
+				if start > 0 {
+					// the coalesced redraws are complete
+					return start, data[progressStart(data[:start]):start], nil
+				}
+				if atEOF {
+					return len(data), data, nil
+				}
+				return 0, nil, nil
+			}
+			i += start
+			end := i + 1
+			switch {
+			case data[i] == '\n':
+			case end < len(data) && data[end] == '\n':
+				end++
+			case end == len(data) && !atEOF:
+				// a "\n" might follow
+				if start > 0 {
+					return start, data[progressStart(data[:start]):start], nil
+				}
+				return 0, nil, nil
+			default:
+				if coalesce {
+					start = end
+					continue
+				}
+				return end, data[:end], nil
+			}
+			if start > 0 {
+				return start, data[progressStart(data[:start]):start], nil
+			}
+			return end, data[:i], nil
+		}
+	}
+}
+
+// progressStart returns the start of the last redraw in data, which ends
+// with "\r".
+func progressStart(data []byte) int {
+	return bytes.LastIndexByte(data[:len(data)-1], '\r') + 1
+}
+
+// progressLine marks i as progress line if it was terminated by a single
+// "\r", which is stripped.
+func progressLine(i streamData) streamData {
+	if n := len(i.data); n > 0 && i.data[n-1] == '\r' {
+		i.data = i.data[:n-1]
+		i.progress = true
+	}
+	return i
+}
@@ -0,0 +1,82 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNulDelimited(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mock   *CommandServiceMock
+		stdout []string
+		stderr []string
+	}{
+		{name: "records", mock: &CommandServiceMock{stdout: "a\x00b\nc\x00"}, stdout: []string{"a", "b\nc"}, stderr: []string{}},
+		{name: "unterminated", mock: &CommandServiceMock{stdout: "a\x00b"}, stdout: []string{"a", "b"}, stderr: []string{}},
+		{name: "empty", mock: &CommandServiceMock{stdout: "a\x00\x00b\x00", stderr: "e\x00"}, stdout: []string{"a", "", "b"}, stderr: []string{"e"}},
+		{name: "none", mock: &CommandServiceMock{}, stdout: []string{}, stderr: []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(tc.mock), WithNulDelimited())
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			event := <-events
+			validateResult(tt, tc.stdout, event.Data().Stdout())
+			validateResult(tt, tc.stderr, event.Data().Stderr())
+			<-cmd.Wait()
+		})
+	}
+}
+
+func TestProgressLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		coalesce bool
+		stdout   []string
+		progress []bool
+	}{
+		{
+			name:     "redraws",
+			output:   "10%\r20%\r30%\rdone\nnext\r\nlast",
+			stdout:   []string{"10%", "20%", "30%", "done", "next", "last"},
+			progress: []bool{true, true, true, false, false, false},
+		},
+		{
+			name:     "coalesced",
+			output:   "10%\r20%\r30%\rdone\n",
+			coalesce: true,
+			stdout:   []string{"30%", "done"},
+			progress: []bool{true, false},
+		},
+		{
+			name:     "trailing",
+			output:   "a\rb\r",
+			stdout:   []string{"a", "b"},
+			progress: []bool{true, true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(&CommandServiceMock{stdout: tc.output}), WithStreaming(), WithProgressLines(tc.coalesce))
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			stdout := []string{}
+			progress := []bool{}
+			for event := range events {
+				stdout = append(stdout, event.Data().Stdout()...)
+				progress = append(progress, event.Data().Progress())
+			}
+			<-cmd.Wait()
+			validateResult(tt, tc.stdout, stdout)
+			validateResult(tt, tc.progress, progress)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// DirError is returned by NewCommand if the working directory set by WithDir
+// does not exist or is not a directory.
+type DirError struct {
+	Dir string
+	Err error
+}
+
+func (e *DirError) Error() string {
+	return fmt.Sprintf("invalid working directory %q: %v", e.Dir, e.Err)
+}
+
+func (e *DirError) Unwrap() error { return e.Err }
+
+// errNotDir is wrapped by a DirError if the path is not a directory.
+var errNotDir = errors.New("not a directory")
+
+// WithDir sets the working directory of the command. The directory must
+// exist when the command is created, otherwise NewCommand returns a
+// *DirError.
+func WithDir(dir string) Option {
+
+	return func(c *Command) error {
+		c.record("WithDir", dir)
+		info, err := os.Stat(dir)
+		if err != nil {
+			return &DirError{Dir: dir, Err: err}
+		}
+		if !info.IsDir() {
+			return &DirError{Dir: dir, Err: errNotDir}
+		}
+		c.configurers = append(c.configurers, func(cmd *exec.Cmd) error {
+			cmd.Dir = dir
+			return nil
+		})
+		return nil
+	}
+}
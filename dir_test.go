@@ -0,0 +1,54 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommandWithDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dir")
+	validateError(t, nil, err)
+	defer os.RemoveAll(dir)
+	dir, err = filepath.EvalSymlinks(dir)
+	validateError(t, nil, err)
+	file := filepath.Join(dir, "file")
+	validateError(t, nil, ioutil.WriteFile(file, nil, 0644))
+	missing := filepath.Join(dir, "missing")
+
+	testCases := []struct {
+		name   string
+		dir    string
+		expect []string
+		err    error
+		cause  error
+	}{
+		{name: "dir", dir: dir, expect: []string{dir}},
+		{name: "missing", dir: missing, err: errors.New(`invalid working directory "` + missing + `": stat ` + missing + `: no such file or directory`), cause: os.ErrNotExist},
+		{name: "file", dir: file, err: errors.New(`invalid working directory "` + file + `": not a directory`), cause: errNotDir},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "pwd", WithDir(tc.dir))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				var dirErr *DirError
+				validateBool(tt, true, errors.As(err, &dirErr))
+				validateResult(tt, tc.dir, dirErr.Dir)
+				validateBool(tt, true, errors.Is(err, tc.cause))
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			event := <-events
+			<-cmd.Wait()
+			validateResult(tt, tc.expect, event.Data().Stdout())
+		})
+	}
+}
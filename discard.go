@@ -0,0 +1,25 @@
+package command
+
+// WithDiscardOutput discards the output of the command, for commands whose
+// output is irrelevant. The channel returned by Execute is closed right away,
+// the events are consumed internally, so Execute followed by Wait is
+// enough. Statistics and the final state are recorded as usual.
+func WithDiscardOutput() Option {
+
+	return func(c *Command) error {
+		c.record("WithDiscardOutput")
+		c.discard = true
+		return nil
+	}
+}
+
+// discardEvents consumes events and returns a closed channel in its place.
+func discardEvents(events <-chan Event) <-chan Event {
+	go func() {
+		for range events {
+		}
+	}()
+	closed := make(chan Event)
+	close(closed)
+	return closed
+}
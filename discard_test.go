@@ -0,0 +1,41 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDiscardOutput(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []interface{}
+		exitCode int
+		lines    int64
+	}{
+		{name: "result", exitCode: 3, lines: 3},
+		{name: "stream", args: []interface{}{WithStreaming()}, exitCode: 3, lines: 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			mock := &CommandServiceMock{stdout: "1\n3\n", stderr: "2\n", errWait: true}
+			args := append([]interface{}{withCommandService(mock), WithDiscardOutput()}, tc.args...)
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			cmd.processState = &processStateMock{exit: 3}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			_, open := <-events
+			validateBool(tt, false, open)
+			state := <-cmd.Wait()
+			validateResult(tt, tc.exitCode, state.ExitCode())
+			stats := state.Stats()
+			validateResult(tt, tc.lines, stats.StdoutLines+stats.StderrLines)
+			result, _, err := cmd.Drain(context.Background())
+			validateError(tt, nil, err)
+			validateResult(tt, 0, len(result.Data.Out()))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package command
+
+import "context"
+
+// ReasonDrainCanceled is the cancellation reason of executions stopped
+// because the context of Drain was done.
+const ReasonDrainCanceled = "drain canceled"
+
+// Drain consumes the remaining events of an executed command, waits for the
+// final state and returns the collected output. It is meant to be called at
+// the end of custom consumption loops to guarantee that the command is
+// cleaned up. If ctx is done first, the command is cancelled with reason
+// ReasonDrainCanceled, drained nevertheless and ctx's error is returned
+// along with the result. Drain returns ErrNotStarted if Execute has not been
+// called.
+func (c *Command) Drain(ctx context.Context) (*Result, State, error) {
+	if c.events == nil {
+		return nil, nil, ErrNotStarted
+	}
+	stdout := []string{}
+	stderr := []string{}
+	var err error
+	done := ctx.Done()
+	events := c.events
+	for events != nil {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			// streamed events carrying an error have no output
+			if c.stream && event.Error() != nil {
+				continue
+			}
+			stdout = append(stdout, event.Data().Stdout()...)
+			stderr = append(stderr, event.Data().Stderr()...)
+		case <-done:
+			err = ctx.Err()
+			done = nil
+			c.CancelWithReason(ReasonDrainCanceled)
+		}
+	}
+	state := <-c.Wait()
+	return &Result{Data: newCommandResult(stdout, stderr), State: state}, state, err
+}
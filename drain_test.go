@@ -0,0 +1,71 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCommandDrain(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []interface{}
+		stream  bool
+		consume int
+		timeout time.Duration
+		stdout  []string
+		stderr  []string
+		reason  string
+		err     error
+	}{
+		{name: "nostream", args: []interface{}{withCommandService(&CommandServiceMock{stdout: "1\n", stderr: "2\n"})}, stdout: []string{"1"}, stderr: []string{"2"}},
+		{name: "stream", args: []interface{}{withCommandService(&CommandServiceMock{stdout: "1\n2\n3\n"})}, stream: true, consume: 1, stdout: []string{"2", "3"}, stderr: []string{}},
+		{name: "streamAll", args: []interface{}{withCommandService(&CommandServiceMock{stdout: "1\n"})}, stream: true, consume: 1, stdout: []string{}, stderr: []string{}},
+		{
+			name:    "timeout",
+			args:    []interface{}{"-c", "echo 1; exec sleep 10"},
+			stream:  true,
+			timeout: time.Millisecond,
+			stdout:  []string{"1"},
+			stderr:  []string{},
+			reason:  ReasonDrainCanceled,
+			err:     context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := tc.args
+			if tc.stream {
+				args = append(args, WithStreaming())
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			for i := 0; i < tc.consume; i++ {
+				<-events
+			}
+			for tc.timeout > 0 && cmd.stats.snapshot().StdoutLines == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			ctx, cancel := createTestContext(tc.timeout)
+			defer cancel()
+			result, state, err := cmd.Drain(ctx)
+			validateError(tt, tc.err, err)
+			validateResult(tt, tc.stdout, result.Data.Stdout())
+			validateResult(tt, tc.stderr, result.Data.Stderr())
+			validateResult(tt, tc.reason, state.CancelReason())
+			validateResult(tt, state, result.State)
+		})
+	}
+}
+
+func TestCommandDrainNotStarted(t *testing.T) {
+	cmd, err := NewCommand(context.Background(), "sh")
+	validateError(t, nil, err)
+	_, _, err = cmd.Drain(context.Background())
+	validateError(t, ErrNotStarted, err)
+}
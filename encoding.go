@@ -0,0 +1,70 @@
+package command
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// Encoding decodes the output of a command to UTF-8. The encodings of
+// golang.org/x/text can be used with EncodingFunc:
+//
+//	command.WithEncoding(command.EncodingFunc(func(r io.Reader) io.Reader {
+//		return charmap.Windows1252.NewDecoder().Reader(r)
+//	}))
+type Encoding interface {
+	Decode(r io.Reader) io.Reader
+}
+
+// EncodingFunc adapts a function to the Encoding interface.
+type EncodingFunc func(r io.Reader) io.Reader
+
+// Decode calls f(r).
+func (f EncodingFunc) Decode(r io.Reader) io.Reader { return f(r) }
+
+// Latin1 decodes ISO-8859-1.
+var Latin1 Encoding = EncodingFunc(func(r io.Reader) io.Reader {
+	return &latin1Reader{r: bufio.NewReader(r)}
+})
+
+// WithEncoding decodes stdout and stderr of the command with e before they
+// are split into lines, for legacy tools which don't print UTF-8. Stats
+// count the bytes before decoding.
+func WithEncoding(e Encoding) Option {
+
+	return func(c *Command) error {
+		c.record("WithEncoding", funcValue)
+		c.encoding = e
+		return nil
+	}
+}
+
+// latin1Reader converts ISO-8859-1 to UTF-8.
+type latin1Reader struct {
+	r *bufio.Reader
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		b, err := l.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		if b >= utf8.RuneSelf && n+2 > len(p) {
+			l.r.UnreadByte()
+			if n == 0 {
+				return 0, io.ErrShortBuffer
+			}
+			break
+		}
+		n += utf8.EncodeRune(p[n:], rune(b))
+		if l.r.Buffered() == 0 {
+			break
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,71 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestWithEncoding(t *testing.T) {
+	upper := EncodingFunc(func(r io.Reader) io.Reader {
+		data, _ := ioutil.ReadAll(r)
+		return bytes.NewReader(bytes.ToUpper(data))
+	})
+	testCases := []struct {
+		name     string
+		mock     *CommandServiceMock
+		encoding Encoding
+		stdout   []string
+		stderr   []string
+	}{
+		{name: "latin1", mock: &CommandServiceMock{stdout: "gr\xfc\xdfe\n", stderr: "caf\xe9\n"}, encoding: Latin1, stdout: []string{"grüße"}, stderr: []string{"café"}},
+		{name: "func", mock: &CommandServiceMock{stdout: "abc\n"}, encoding: upper, stdout: []string{"ABC"}, stderr: []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(tc.mock), WithEncoding(tc.encoding))
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			event := <-events
+			validateResult(tt, tc.stdout, event.Data().Stdout())
+			validateResult(tt, tc.stderr, event.Data().Stderr())
+			<-cmd.Wait()
+		})
+	}
+}
+
+func TestLatin1(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		size   int
+		expect string
+	}{
+		{name: "ascii", input: "abc", size: 1, expect: "abc"},
+		{name: "large", input: "\xe4" + strings.Repeat("x", 5000) + "\xfc", size: 4096, expect: "ä" + strings.Repeat("x", 5000) + "ü"},
+		{name: "small", input: "a\xe4b", size: 2, expect: "aäb"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			r := Latin1.Decode(strings.NewReader(tc.input))
+			var out []byte
+			buf := make([]byte, tc.size)
+			for {
+				n, err := r.Read(buf)
+				out = append(out, buf[:n]...)
+				if err == io.EOF {
+					break
+				}
+				validateError(tt, nil, err)
+			}
+			validateResult(tt, tc.expect, string(out))
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// WithEnv sets environment variables given as "key=value" pairs. By default
+// the process inherits the environment of the caller, see WithInheritEnv.
+func WithEnv(env []string) Option {
+
+	return func(c *Command) error {
+		c.record("WithEnv", redactEnv(env))
+		c.env = append(c.env, env...)
+		c.addEnvConfigurer()
+		return nil
+	}
+}
+
+// WithEnvMap sets the environment variables of env. See WithEnv.
+func WithEnvMap(env map[string]string) Option {
+
+	return func(c *Command) error {
+		c.record("WithEnvMap", redactedMap(env))
+		c.env = mergeEnv(c.env, env)
+		c.addEnvConfigurer()
+		return nil
+	}
+}
+
+// WithInheritEnv controls whether the process inherits the environment of the
+// caller (the default). If inherit is false the process only gets the
+// variables set by options.
+func WithInheritEnv(inherit bool) Option {
+
+	return func(c *Command) error {
+		c.record("WithInheritEnv", inherit)
+		c.replaceEnv = !inherit
+		c.addEnvConfigurer()
+		return nil
+	}
+}
+
+// addEnvConfigurer registers the setup of the static environment once.
+func (c *Command) addEnvConfigurer() {
+	if c.envConfigured {
+		return
+	}
+	c.envConfigured = true
+	c.configurers = append(c.configurers, func(cmd *exec.Cmd) error {
+		base := []string{}
+		if !c.replaceEnv {
+			base = os.Environ()
+		}
+		cmd.Env = overrideEnv(base, c.env)
+		return nil
+	})
+}
+
+// WithEnvFunc sets environment variables returned by fn. Unlike static
+// options fn is evaluated when the command is executed, so short-lived
+// credentials can be fetched fresh for every run. An error returned by fn
+// aborts Execute.
+func WithEnvFunc(fn func(ctx context.Context) (map[string]string, error)) Option {
+
+	return func(c *Command) error {
+		c.record("WithEnvFunc", funcValue)
+		c.envFuncs = append(c.envFuncs, fn)
+		return nil
+	}
+}
+
+// applyEnv evaluates the environment functions and sets the resulting
+// environment on the underlying exec.Cmd.
+func (c *Command) applyEnv() error {
+	if len(c.envFuncs) == 0 {
+		return nil
+	}
+	cmd, ok := c.cmd.(*exec.Cmd)
+	if !ok {
+		return nil
+	}
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	for _, fn := range c.envFuncs {
+		vars, err := fn(c.ctx)
+		if err != nil {
+			return err
+		}
+		env = mergeEnv(env, vars)
+	}
+	cmd.Env = env
+	return nil
+}
+
+// mergeEnv returns env with vars set, replacing existing definitions.
+func mergeEnv(env []string, vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]string, 0, len(vars))
+	for _, k := range keys {
+		kvs = append(kvs, k+"="+vars[k])
+	}
+	return overrideEnv(env, kvs)
+}
+
+// overrideEnv returns env with the "key=value" pairs of kvs appended,
+// removing earlier definitions of the same keys.
+func overrideEnv(env, kvs []string) []string {
+	set := make(map[string]bool, len(kvs))
+	for _, kv := range kvs {
+		set[envKey(kv)] = true
+	}
+	out := make([]string, 0, len(env)+len(kvs))
+	for _, kv := range env {
+		if !set[envKey(kv)] {
+			out = append(out, kv)
+		}
+	}
+	for i, kv := range kvs {
+		if !redefined(kvs[i+1:], envKey(kv)) {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// redefined reports whether key is defined in kvs.
+func redefined(kvs []string, key string) bool {
+	for _, kv := range kvs {
+		if envKey(kv) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func envKey(kv string) string {
+	if i := strings.IndexByte(kv, '='); i >= 0 {
+		return kv[:i]
+	}
+	return kv
+}
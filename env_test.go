@@ -0,0 +1,126 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeEnv(t *testing.T) {
+	testCases := []struct {
+		name   string
+		env    []string
+		vars   map[string]string
+		expect []string
+	}{
+		{name: "append", env: []string{"A=1"}, vars: map[string]string{"C": "3", "B": "2"}, expect: []string{"A=1", "B=2", "C=3"}},
+		{name: "replace", env: []string{"A=1", "B=1"}, vars: map[string]string{"A": "2"}, expect: []string{"B=1", "A=2"}},
+		{name: "empty", env: []string{"A=1"}, expect: []string{"A=1"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			validateResult(tt, tc.expect, mergeEnv(tc.env, tc.vars))
+		})
+	}
+}
+
+func TestCommandEnvFunc(t *testing.T) {
+	calls := 0
+	token := func(ctx context.Context) (map[string]string, error) {
+		calls++
+		return map[string]string{"TOKEN": "fresh"}, nil
+	}
+	testCases := []struct {
+		name   string
+		opts   []interface{}
+		expect []string
+		err    error
+	}{
+		{name: "env", opts: []interface{}{WithEnvFunc(token)}, expect: []string{"fresh"}},
+		{name: "error", opts: []interface{}{WithEnvFunc(func(context.Context) (map[string]string, error) { return nil, errors.New("errEnv") })}, err: errors.New("errEnv")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append(tc.opts, "-c", "echo $TOKEN")...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			event := <-events
+			validateResult(tt, tc.expect, event.Data().Stdout())
+			<-cmd.Wait()
+		})
+	}
+	validateResult(t, 1, calls)
+}
+
+func TestCommandIsolatedHome(t *testing.T) {
+	cmd, err := NewCommand(context.Background(), "sh", WithIsolatedHome(), "-c", "echo $HOME; echo $XDG_CONFIG_HOME; touch $HOME/dotfile && echo ok")
+	validateError(t, nil, err)
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	event := <-events
+	<-cmd.Wait()
+
+	out := event.Data().Stdout()
+	validateResult(t, 3, len(out))
+	home := out[0]
+	validateBool(t, true, home != os.Getenv("HOME"))
+	validateResult(t, filepath.Join(home, ".config"), out[1])
+	validateResult(t, "ok", out[2])
+	_, err = os.Stat(home)
+	validateBool(t, true, os.IsNotExist(err))
+}
+
+func TestOverrideEnv(t *testing.T) {
+	testCases := []struct {
+		name   string
+		env    []string
+		kvs    []string
+		expect []string
+	}{
+		{name: "append", env: []string{"A=1"}, kvs: []string{"B=2"}, expect: []string{"A=1", "B=2"}},
+		{name: "replace", env: []string{"A=1", "B=1"}, kvs: []string{"A=2"}, expect: []string{"B=1", "A=2"}},
+		{name: "duplicate", kvs: []string{"A=1", "B=1", "A=2"}, expect: []string{"B=1", "A=2"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			validateResult(tt, tc.expect, overrideEnv(tc.env, tc.kvs))
+		})
+	}
+}
+
+func TestCommandEnv(t *testing.T) {
+	os.Setenv("COMMAND_TEST_PARENT", "parent")
+	defer os.Unsetenv("COMMAND_TEST_PARENT")
+	testCases := []struct {
+		name   string
+		opts   []interface{}
+		expect []string
+	}{
+		{name: "inherit", expect: []string{"parent", "", ""}},
+		{name: "env", opts: []interface{}{WithEnv([]string{"A=a", "B=b"})}, expect: []string{"parent", "a", "b"}},
+		{name: "envMap", opts: []interface{}{WithEnvMap(map[string]string{"A": "a"}), WithEnv([]string{"B=b"})}, expect: []string{"parent", "a", "b"}},
+		{name: "override", opts: []interface{}{WithEnv([]string{"A=a", "COMMAND_TEST_PARENT=child"}), WithEnvMap(map[string]string{"A": "x"})}, expect: []string{"child", "x", ""}},
+		{name: "replace", opts: []interface{}{WithEnv([]string{"A=a"}), WithInheritEnv(false)}, expect: []string{"", "a", ""}},
+		{name: "empty", opts: []interface{}{WithInheritEnv(false)}, expect: []string{"", "", ""}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "/bin/sh", append(tc.opts, "-c", `echo "$COMMAND_TEST_PARENT"; echo "$A"; echo "$B"`)...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			event := <-events
+			<-cmd.Wait()
+			validateResult(tt, tc.expect, event.Data().Stdout())
+		})
+	}
+}
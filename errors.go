@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// Sentinel errors which classify the errors returned by Execute,
+// ExecuteStatus and State.Error. Use errors.Is to test for them; the
+// original error, e.g. an *exec.ExitError, is still available with
+// errors.As.
+var (
+	// ErrStartFailed is matched if the process could not be started.
+	ErrStartFailed = newCodedError("START_FAILED", "start failed")
+
+	// ErrPipe is matched if an output or input pipe could not be created.
+	ErrPipe = newCodedError("PIPE_FAILED", "pipe failed")
+
+	// ErrCanceled is matched if the command was cancelled by its context or
+	// by CancelWithReason.
+	ErrCanceled = newCodedError("CANCELED", "canceled")
+
+	// ErrDeadline is matched if the command was stopped because the deadline
+	// of its context was exceeded.
+	ErrDeadline = newCodedError("DEADLINE_EXCEEDED", "deadline exceeded")
+
+	// ErrNonZeroExit is matched if the process exited with a non-zero code
+	// or was killed. It wraps an *exec.ExitError.
+	ErrNonZeroExit = newCodedError("NON_ZERO_EXIT", "non-zero exit")
+)
+
+// kindError classifies err with sentinel errors without changing its
+// message.
+type kindError struct {
+	err   error
+	kinds []error
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+
+func (e *kindError) Unwrap() error { return e.err }
+
+func (e *kindError) Is(target error) bool {
+	for _, kind := range e.kinds {
+		if kind == target {
+			return true
+		}
+	}
+	return false
+}
+
+// withKind classifies err with kinds. It returns nil if err is nil.
+func withKind(err error, kinds ...error) error {
+	if err == nil || len(kinds) == 0 {
+		return err
+	}
+	return &kindError{err: err, kinds: kinds}
+}
+
+// Is reports whether target is ErrCanceled.
+func (e *CancelError) Is(target error) bool {
+	return target == ErrCanceled
+}
+
+// contextError classifies an error of the command's context.
+func contextError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return withKind(err, ErrDeadline)
+	case errors.Is(err, context.Canceled):
+		return withKind(err, ErrCanceled)
+	}
+	return err
+}
+
+// waitError classifies the error returned by Wait using the cancellation
+// reason of the command.
+func waitError(err error, reason string) error {
+	kinds := []error{}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		kinds = append(kinds, ErrNonZeroExit)
+	}
+	switch reason {
+	case "":
+	case ReasonDeadline:
+		kinds = append(kinds, ErrDeadline)
+	default:
+		kinds = append(kinds, ErrCanceled)
+	}
+	return withKind(err, kinds...)
+}
@@ -0,0 +1,85 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestCommandErrorKinds(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []interface{}
+		deadline bool
+		cancel   string
+		startErr error
+		stateErr []error
+	}{
+		{name: "success", args: []interface{}{withCommandService(&CommandServiceMock{})}},
+		{name: "nonZero", args: []interface{}{"-c", "exit 3"}, stateErr: []error{ErrNonZeroExit}},
+		{name: "deadline", args: []interface{}{"-c", "exec sleep 10"}, deadline: true, stateErr: []error{ErrNonZeroExit, ErrDeadline}},
+		{name: "canceled", args: []interface{}{"-c", "exec sleep 10"}, cancel: "test", stateErr: []error{ErrNonZeroExit, ErrCanceled}},
+		{name: "pipe", args: []interface{}{withCommandService(&CommandServiceMock{errStdoutPipe: true})}, startErr: ErrPipe},
+		{name: "start", args: []interface{}{withCommandService(&CommandServiceMock{errStart: true})}, startErr: ErrStartFailed},
+	}
+	kinds := []error{ErrStartFailed, ErrPipe, ErrCanceled, ErrDeadline, ErrNonZeroExit}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx := newExpiringContext()
+			defer ctx.expire()
+			cmd, err := NewCommand(ctx, "sh", tc.args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			if tc.startErr != nil {
+				validateBool(tt, true, errors.Is(err, tc.startErr))
+				return
+			}
+			validateError(tt, nil, err)
+			if tc.cancel != "" {
+				cmd.CancelWithReason(tc.cancel)
+			}
+			if tc.deadline {
+				ctx.expire()
+			}
+			for range events {
+			}
+			err = (<-cmd.Wait()).Error()
+			for _, kind := range kinds {
+				validateResult(tt, contains(tc.stateErr, kind), errors.Is(err, kind))
+			}
+			var exitErr *exec.ExitError
+			validateBool(tt, len(tc.stateErr) > 0, errors.As(err, &exitErr))
+		})
+	}
+}
+
+func TestCommandErrorKindsNotFound(t *testing.T) {
+	dir, err := ioutil.TempDir("", "errorkinds")
+	validateError(t, nil, err)
+	defer os.RemoveAll(dir)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir)
+
+	cmd, err := NewCommand(context.Background(), "command-which-does-not-exist")
+	validateError(t, nil, err)
+	_, err = cmd.Execute()
+	validateBool(t, true, errors.Is(err, ErrStartFailed))
+	validateBool(t, true, errors.Is(err, exec.ErrNotFound))
+	var notFound *NotFoundError
+	validateBool(t, true, errors.As(err, &notFound))
+}
+
+func contains(errs []error, target error) bool {
+	for _, err := range errs {
+		if err == target {
+			return true
+		}
+	}
+	return false
+}
@@ -20,11 +20,12 @@ func ExampleCommand_Wait() {
 
 	// Wait returns an exit code and error information. Once read from the
 	// channel, resources are freed.
-	fmt.Println(<-cmd.Wait())
+	state := <-cmd.Wait()
+	fmt.Println(state.ExitCode(), state.Error())
 
 	// Output:
 	// hello
-	// &{0 <nil>}
+	// 0 <nil>
 }
 
 func ExampleNewCommandStream() {
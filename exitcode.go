@@ -0,0 +1,74 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ExitCodeError is the error of the final State and of the last event if
+// WithErrOnNonZeroExit is set and the command exited with a non-zero code.
+// It wraps the error returned by Wait.
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", e.Code)
+}
+
+func (e *ExitCodeError) Unwrap() error { return e.Err }
+
+// WithErrOnNonZeroExit reports a non-zero exit code as an *ExitCodeError on
+// the final State and on the last event, so callers do not need to check
+// the exit code separately. In streaming mode an additional event carrying
+// the error is emitted after the output; in non-streaming mode the error is
+// set on the result event. Either way the last event is delivered only
+// after the process exited.
+func WithErrOnNonZeroExit() Option {
+
+	return func(c *Command) error {
+		c.record("WithErrOnNonZeroExit")
+		c.errOnExit = true
+		return nil
+	}
+}
+
+// WithSuccessExitCodes sets exit codes besides 0 which indicate success,
+// like 1 for grep (no match) or diff (differences found). If
+// WithErrOnNonZeroExit is set, the final State and the last event carry no
+// error for these codes.
+func WithSuccessExitCodes(codes ...int) Option {
+
+	return func(c *Command) error {
+		c.record("WithSuccessExitCodes", codes)
+		for _, code := range codes {
+			if code < 0 || code > 255 {
+				return fmt.Errorf("invalid exit code: %d", code)
+			}
+		}
+		c.successCodes = append(c.successCodes, codes...)
+		return nil
+	}
+}
+
+// successCode reports whether code was set by WithSuccessExitCodes.
+func (c *Command) successCode(code int) bool {
+	for _, v := range c.successCodes {
+		if v == code {
+			return true
+		}
+	}
+	return false
+}
+
+// exitCodeError returns the *ExitCodeError of the final state, or nil.
+// It blocks until the process has exited.
+func (c *Command) exitCodeError() error {
+	<-c.exited
+	var exitErr *ExitCodeError
+	if errors.As(c.exitState.Error(), &exitErr) {
+		return exitErr
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestCommandErrOnNonZeroExit(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []interface{}
+		exit   int
+		stream bool
+		opts   []interface{}
+		last   error
+		state  error
+	}{
+		{name: "success", args: []interface{}{withCommandService(&CommandServiceMock{stdout: "out\n"})}, opts: []interface{}{WithErrOnNonZeroExit()}, last: errors.New("no error")},
+		{name: "failure", args: []interface{}{"-c", "echo out; exit 3"}, opts: []interface{}{WithErrOnNonZeroExit()}, last: &ExitCodeError{Code: 3}, state: &ExitCodeError{Code: 3}},
+		{name: "streamSuccess", args: []interface{}{withCommandService(&CommandServiceMock{stdout: "out\n"})}, stream: true, opts: []interface{}{WithErrOnNonZeroExit()}},
+		{name: "streamFailure", args: []interface{}{"-c", "echo out; exit 3"}, stream: true, opts: []interface{}{WithErrOnNonZeroExit()}, last: &ExitCodeError{Code: 3}, state: &ExitCodeError{Code: 3}},
+		{name: "successCode", args: []interface{}{withCommandService(&CommandServiceMock{errWait: true})}, exit: 1, opts: []interface{}{WithErrOnNonZeroExit(), WithSuccessExitCodes(1, 2)}, last: errors.New("no error")},
+		{name: "streamSuccessCode", args: []interface{}{withCommandService(&CommandServiceMock{stdout: "out\n", errWait: true})}, exit: 1, stream: true, opts: []interface{}{WithErrOnNonZeroExit(), WithSuccessExitCodes(1)}},
+		{name: "otherCode", args: []interface{}{"-c", "exit 3"}, opts: []interface{}{WithErrOnNonZeroExit(), WithSuccessExitCodes(1)}, last: &ExitCodeError{Code: 3}, state: &ExitCodeError{Code: 3}},
+		{name: "successCodeDisabled", args: []interface{}{"-c", "exit 1"}, opts: []interface{}{WithSuccessExitCodes(1)}, last: errors.New("no error"), state: errors.New("exit status 1")},
+		{name: "disabled", args: []interface{}{"-c", "echo out; exit 3"}, last: errors.New("no error"), state: errors.New("exit status 3")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := append(append([]interface{}{}, tc.args...), tc.opts...)
+			if tc.stream {
+				args = append(args, WithStreaming())
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			if tc.exit != 0 {
+				cmd.processState = &processStateMock{exit: tc.exit}
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			var last Event
+			for event := range events {
+				last = event
+			}
+			validateError(tt, tc.last, last.Error())
+			state := <-cmd.Wait()
+			validateError(tt, tc.state, state.Error())
+			if tc.state != nil {
+				var exitErr *exec.ExitError
+				validateBool(tt, true, errors.As(state.Error(), &exitErr))
+				validateBool(tt, true, errors.Is(state.Error(), ErrNonZeroExit))
+			}
+		})
+	}
+}
+
+func TestWithSuccessExitCodesInvalid(t *testing.T) {
+	_, err := NewCommand(context.Background(), "sh", WithSuccessExitCodes(1, -1))
+	validateError(t, errors.New("invalid exit code: -1"), err)
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+)
+
+// WithExtraFiles passes files to the process as additional open file
+// descriptors. The i-th file becomes descriptor 3+i in the process, e.g. the
+// first file is fd 3. The option can be used multiple times, descriptors
+// are numbered in the order of the files.
+//
+// The command takes ownership of the files: they are closed after the
+// process exited, or if Execute fails to start it.
+func WithExtraFiles(files ...*os.File) Option {
+
+	return func(c *Command) error {
+		c.record("WithExtraFiles", len(files), "files")
+		c.configurers = append(c.configurers, func(cmd *exec.Cmd) error {
+			cmd.ExtraFiles = append(cmd.ExtraFiles, files...)
+			return nil
+		})
+		c.cleanups = append(c.cleanups, func() {
+			for _, f := range files {
+				f.Close()
+			}
+		})
+		return nil
+	}
+}
@@ -0,0 +1,37 @@
+// +build !integration
+// +build unit
+// +build linux
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCommandExtraFiles(t *testing.T) {
+	in, inWriter, err := os.Pipe()
+	validateError(t, nil, err)
+	outReader, out, err := os.Pipe()
+	validateError(t, nil, err)
+	defer outReader.Close()
+
+	cmd, err := NewCommand(context.Background(), "sh", "-c", "read line <&3; echo \"got $line\" >&4", WithExtraFiles(in), WithExtraFiles(out))
+	validateError(t, nil, err)
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	inWriter.WriteString("hello\n")
+	inWriter.Close()
+	for range events {
+	}
+	validateResult(t, 0, (<-cmd.Wait()).ExitCode())
+
+	// the write end held by the command is closed after exit
+	data, err := ioutil.ReadAll(outReader)
+	validateError(t, nil, err)
+	validateResult(t, "got hello\n", string(data))
+	_, err = in.Stat()
+	validateBool(t, true, err != nil)
+}
@@ -0,0 +1,40 @@
+package command
+
+import "fmt"
+
+// StderrError is the final error of a command which exited successfully but
+// wrote more stderr lines than allowed by WithStderrMeansFailure.
+type StderrError struct {
+	// Lines is the number of lines written to stderr.
+	Lines int64
+}
+
+func (e *StderrError) Error() string {
+	return fmt.Sprintf("command wrote %d lines to stderr", e.Lines)
+}
+
+// WithStderrMeansFailure marks the final State as failed if the command
+// writes more than threshold lines to stderr, even if it exits with code 0.
+// A threshold of 0 treats any stderr output as failure. The State's Error
+// returns a *StderrError in this case.
+func WithStderrMeansFailure(threshold int) Option {
+
+	return func(c *Command) error {
+		c.record("WithStderrMeansFailure", threshold)
+		if threshold < 0 {
+			return fmt.Errorf("invalid stderr threshold: %d", threshold)
+		}
+		c.stderrFailure = true
+		c.stderrThreshold = int64(threshold)
+		return nil
+	}
+}
+
+// checkStderr returns a *StderrError if stats exceed the configured stderr
+// threshold.
+func (c *Command) checkStderr(stats Stats) error {
+	if c.stderrFailure && stats.StderrLines > c.stderrThreshold {
+		return &StderrError{Lines: stats.StderrLines}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCommandStderrMeansFailure(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   []interface{}
+		mock   *CommandServiceMock
+		expect error
+	}{
+		{name: "disabled", mock: &CommandServiceMock{stderr: "warn"}},
+		{name: "anyStderr", opts: []interface{}{WithStderrMeansFailure(0)}, mock: &CommandServiceMock{stderr: "warn"}, expect: &StderrError{Lines: 1}},
+		{name: "noStderr", opts: []interface{}{WithStderrMeansFailure(0)}, mock: &CommandServiceMock{stdout: "out"}},
+		{name: "belowThreshold", opts: []interface{}{WithStderrMeansFailure(2)}, mock: &CommandServiceMock{stderr: "1\n2"}},
+		{name: "aboveThreshold", opts: []interface{}{WithStderrMeansFailure(2)}, mock: &CommandServiceMock{stderr: "1\n2\n3"}, expect: &StderrError{Lines: 3}},
+		{name: "waitError", opts: []interface{}{WithStderrMeansFailure(0)}, mock: &CommandServiceMock{stderr: "1", errWait: true}, expect: errors.New("errWait")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append(tc.opts, withCommandService(tc.mock))...)
+			validateError(tt, nil, err)
+			cmd.processState = &processStateMock{}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			for range events {
+			}
+			state := <-cmd.Wait()
+			validateError(tt, tc.expect, state.Error())
+			validateResult(tt, 0, state.ExitCode())
+		})
+	}
+
+	_, err := NewCommand(context.Background(), "sh", WithStderrMeansFailure(-1))
+	validateError(t, errors.New("invalid stderr threshold: -1"), err)
+}
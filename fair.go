@@ -0,0 +1,185 @@
+package command
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithFairMerge merges stdout and stderr round-robin: each round forwards at
+// most quota pending lines of every stream before moving on to the next
+// one. Without it, a flooding stream can starve the other one, so that an
+// interactive consumer doesn't see stdout while stderr floods.
+func WithFairMerge(quota int) Option {
+
+	return func(c *Command) error {
+		c.record("WithFairMerge", quota)
+		if quota < 1 {
+			return fmt.Errorf("invalid merge quota: %d", quota)
+		}
+		c.fairQuota = quota
+		return nil
+	}
+}
+
+// forwarder forwards the lines of a single stream to the merged stream.
+type forwarder struct {
+	c       *Command
+	sampler *lineSampler
+	emit    func(streamData) bool
+
+	// partial is set if the last line was partial
+	partial bool
+
+	// keep is the filter decision for the current line (see WithLineFilter)
+	keep bool
+}
+
+func (c *Command) newForwarder(emit func(streamData) bool) *forwarder {
+	return &forwarder{c: c, sampler: c.newSampler(), emit: emit}
+}
+
+// forward records and emits i, its matches (see WithMatcher) and its
+// progress (see WithProgressParsers). It returns false if the merged stream
+// is done.
+func (f *forwarder) forward(i streamData) bool {
+	if f.c.progressLines {
+		i = progressLine(i)
+	}
+	i = f.c.checkUTF8(i)
+	var matches []*Match
+	var progress *ProgressEvent
+	if i.err != nil {
+		if f.c.daemon != nil && f.c.daemon.closedPipe(i.err) {
+			return true
+		}
+		f.c.addReadError(i.err)
+	} else {
+		if !i.partial {
+			f.c.stats.addLine(i.isStderr)
+		}
+		if i.isStderr && f.c.classifier != nil {
+			f.c.classifier.observe(i.data)
+		}
+		if f.c.daemon != nil {
+			f.c.daemon.observe(i.data)
+		}
+		if len(f.c.matchers) > 0 {
+			matches = f.c.match(i)
+		}
+		if len(f.c.progressParsers) > 0 {
+			progress = f.c.parseProgress(i)
+		}
+	}
+	if !f.forwardLine(i) {
+		return false
+	}
+	for _, match := range matches {
+		if !f.emit(streamData{match: match, isStderr: i.isStderr, at: i.at}) {
+			return false
+		}
+	}
+	if progress != nil {
+		return f.emit(streamData{report: progress, isStderr: i.isStderr, at: i.at})
+	}
+	return true
+}
+
+// forwardLine filters, transforms, decorates and emits i. It returns false
+// if the merged stream is done.
+func (f *forwarder) forwardLine(i streamData) bool {
+	if i.err == nil {
+		continued := f.partial
+		f.partial = i.partial
+		if f.c.lineFilter != nil && !f.keepLine(i, continued) {
+			if !i.partial {
+				f.c.stats.addFiltered()
+			}
+			return true
+		}
+		if len(f.c.transforms) > 0 {
+			i = f.c.transform(i)
+		}
+		if (f.c.linePrefix != "" || f.c.timestamps != nil) && !continued {
+			i = f.decorate(i)
+		}
+	}
+	if f.sampler != nil && !i.isStderr && i.err == nil && !f.sampler.keep(i) {
+		return true
+	}
+	return f.emit(i)
+}
+
+// finish emits the held back last line of a sampled stream.
+func (f *forwarder) finish() {
+	if f.sampler == nil {
+		return
+	}
+	if last, ok := f.sampler.last(); ok {
+		f.emit(last)
+	}
+	f.c.stats.addSkipped(f.sampler.skipped)
+}
+
+// fairMerge forwards channels round-robin with at most c.fairQuota lines
+// per channel and round. If no channel has a pending line it blocks until
+// one has.
+func (c *Command) fairMerge(channels []<-chan streamData, emit func(streamData) bool) {
+	open := make([]<-chan streamData, len(channels))
+	copy(open, channels)
+	remaining := len(open)
+	forwarders := make([]*forwarder, len(channels))
+	for n := range forwarders {
+		forwarders[n] = c.newForwarder(emit)
+	}
+	// receive returns false if the merged stream is done.
+	receive := func(n int, i streamData, ok bool) bool {
+		if !ok {
+			forwarders[n].finish()
+			open[n] = nil
+			remaining--
+			return true
+		}
+		return forwarders[n].forward(i)
+	}
+	cases := make([]reflect.SelectCase, len(open))
+	for remaining > 0 {
+		received := false
+		for n := range open {
+			for q := 0; open[n] != nil && q < c.fairQuota; q++ {
+				i, ok, ready := tryReceive(open[n])
+				if !ready {
+					break
+				}
+				received = true
+				if !receive(n, i, ok) {
+					return
+				}
+			}
+		}
+		if received {
+			continue
+		}
+		for n, ch := range open {
+			cases[n] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)}
+		}
+		n, v, ok := reflect.Select(cases)
+		var i streamData
+		if ok {
+			i = v.Interface().(streamData)
+		}
+		if !receive(n, i, ok) {
+			return
+		}
+	}
+}
+
+// tryReceive receives from ch without blocking. ready is false if no value
+// is pending.
+func tryReceive(ch <-chan streamData) (i streamData, ok, ready bool) {
+	select {
+	case i, ok = <-ch:
+		return i, ok, true
+	default:
+		return i, false, false
+	}
+}
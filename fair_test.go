@@ -0,0 +1,65 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFairMerge(t *testing.T) {
+	testCases := []struct {
+		name   string
+		quota  int
+		sample int
+		order  string
+		stdout []string
+		err    error
+	}{
+		{name: "quota1", quota: 1, order: "eoeoeee", stdout: []string{"1", "2"}},
+		{name: "quota2", quota: 2, order: "eeooeee", stdout: []string{"1", "2"}},
+		{name: "quota10", quota: 10, order: "eeeeeoo", stdout: []string{"1", "2"}},
+		{name: "sampled", quota: 1, sample: 2, order: "eoeeoee", stdout: []string{"1", "2"}},
+		{name: "invalid", quota: 0, err: errors.New("invalid merge quota: 0")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := []interface{}{WithStreaming(), WithFairMerge(tc.quota)}
+			if tc.sample > 0 {
+				args = append(args, WithSampleLines(tc.sample))
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			// the lines of both streams are pending, stderr floods
+			stderr := make(chan streamData, 5)
+			for i := 0; i < cap(stderr); i++ {
+				stderr <- *newStreamData("e", true)
+			}
+			close(stderr)
+			stdout := make(chan streamData, 2)
+			stdout <- *newStreamData("1", false)
+			stdout <- *newStreamData("2", false)
+			close(stdout)
+
+			var order strings.Builder
+			lines := []string{}
+			cmd.fairMerge([]<-chan streamData{stderr, stdout}, func(i streamData) bool {
+				if i.isStderr {
+					order.WriteString("e")
+				} else {
+					order.WriteString("o")
+					lines = append(lines, i.data)
+				}
+				return true
+			})
+			validateResult(tt, tc.order, order.String())
+			validateResult(tt, tc.stdout, lines)
+		})
+	}
+}
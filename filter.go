@@ -0,0 +1,62 @@
+package command
+
+import "fmt"
+
+// WithLineFilter drops every line for which keep returns false before it
+// is delivered as event or collected in the result, e.g. to reduce the
+// output of noisy commands. The filter sees the undecorated line (see
+// WithLinePrefix) and may be called concurrently for stdout and stderr.
+// Lines split by WithUnboundedLines are kept or dropped as a whole based on the
+// first segment, chunks are never dropped. Dropped lines are counted by
+// Stats.FilteredLines.
+func WithLineFilter(keep func(line string, isStderr bool) bool) Option {
+
+	return func(c *Command) error {
+		c.record("WithLineFilter", funcValue)
+		if keep == nil {
+			return fmt.Errorf("filter cannot be nil")
+		}
+		c.lineFilter = keep
+		return nil
+	}
+}
+
+// keepLine applies the line filter to i. Continued segments of a line share
+// the decision of the first one.
+func (f *forwarder) keepLine(i streamData, continued bool) bool {
+	if !continued {
+		f.keep = i.chunk != nil || f.c.lineFilter(i.data, i.isStderr)
+	}
+	return f.keep
+}
+
+// WithLineTransform replaces every line by the result of fn before it is
+// delivered as event or collected in the result, e.g. to trim lines,
+// normalize paths or mask values. It is applied after the line filter (see
+// WithLineFilter) and before the line is decorated (see WithLinePrefix).
+// The option can be used multiple times, the transforms are applied in
+// order. Like the filter, fn may be called concurrently for stdout and
+// stderr; it is called for every segment of a line split by
+// WithUnboundedLines and not for chunks.
+func WithLineTransform(fn func(line string) string) Option {
+
+	return func(c *Command) error {
+		c.record("WithLineTransform", funcValue)
+		if fn == nil {
+			return fmt.Errorf("transform cannot be nil")
+		}
+		c.transforms = append(c.transforms, fn)
+		return nil
+	}
+}
+
+// transform applies the line transforms to i.
+func (c *Command) transform(i streamData) streamData {
+	if i.chunk != nil {
+		return i
+	}
+	for _, fn := range c.transforms {
+		i.data = fn(i.data)
+	}
+	return i
+}
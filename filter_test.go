@@ -0,0 +1,134 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLineFilter(t *testing.T) {
+	noDebug := func(line string, isStderr bool) bool {
+		return !strings.HasPrefix(line, "debug")
+	}
+	testCases := []struct {
+		name     string
+		args     []interface{}
+		mock     *CommandServiceMock
+		filter   func(string, bool) bool
+		lines    []string
+		filtered int64
+	}{
+		{
+			name:     "result",
+			mock:     &CommandServiceMock{stdout: "debug 1\ninfo 2", stderr: "debug 3\nwarn 4"},
+			filter:   noDebug,
+			lines:    []string{"info 2", "warn 4"},
+			filtered: 2,
+		},
+		{
+			name:     "stream",
+			args:     []interface{}{WithStreaming(), WithLinePrefix("web")},
+			mock:     &CommandServiceMock{stdout: "debug 1\ninfo 2"},
+			filter:   noDebug,
+			lines:    []string{"[web] info 2"},
+			filtered: 1,
+		},
+		{
+			name: "stderrOnly",
+			args: []interface{}{WithStreaming()},
+			mock: &CommandServiceMock{stdout: "1", stderr: "2"},
+			filter: func(line string, isStderr bool) bool {
+				return isStderr
+			},
+			lines:    []string{"2"},
+			filtered: 1,
+		},
+		{
+			name:     "unboundedLines",
+			args:     []interface{}{WithStreaming(), WithUnboundedLines(16)},
+			mock:     &CommandServiceMock{stdout: "debug6789abcdefXYZ\ninfo6789abcdefXYZ"},
+			filter:   noDebug,
+			lines:    []string{"info6789abcdefXY", "Z"},
+			filtered: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := append([]interface{}{withCommandService(tc.mock), WithLineFilter(tc.filter)}, tc.args...)
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			lines := []string{}
+			for event := range events {
+				lines = append(lines, event.Data().Out()...)
+			}
+			validateResult(tt, tc.lines, lines)
+			validateResult(tt, tc.filtered, (<-cmd.Wait()).Stats().FilteredLines)
+		})
+	}
+}
+
+func TestLineFilterNil(t *testing.T) {
+	_, err := NewCommand(context.Background(), "true", WithLineFilter(nil))
+	validateError(t, errors.New("filter cannot be nil"), err)
+}
+
+func TestLineTransform(t *testing.T) {
+	testCases := []struct {
+		name       string
+		args       []interface{}
+		transforms []func(string) string
+		lines      []string
+		err        error
+	}{
+		{
+			name:       "result",
+			transforms: []func(string) string{strings.TrimSpace},
+			lines:      []string{"1", "2"},
+		},
+		{
+			name: "ordered",
+			args: []interface{}{WithStreaming(), WithLinePrefix("web")},
+			transforms: []func(string) string{func(line string) string {
+				return line + "!"
+			}, strings.TrimSpace},
+			lines: []string{"[web] 1  !", "[web] 2  !"},
+		},
+		{
+			name: "afterFilter",
+			args: []interface{}{WithLineFilter(func(line string, isStderr bool) bool {
+				return strings.HasPrefix(line, "  2")
+			})},
+			transforms: []func(string) string{func(line string) string {
+				return strings.Replace(line, "2", "x", 1)
+			}},
+			lines: []string{"  x  "},
+		},
+		{name: "nil", transforms: []func(string) string{nil}, err: errors.New("transform cannot be nil")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := append([]interface{}{withCommandService(&CommandServiceMock{stdout: "  1  \n  2  \n"})}, tc.args...)
+			for _, fn := range tc.transforms {
+				args = append(args, WithLineTransform(fn))
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			lines := []string{}
+			for event := range events {
+				lines = append(lines, event.Data().Out()...)
+			}
+			validateResult(tt, tc.lines, lines)
+		})
+	}
+}
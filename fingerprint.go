@@ -0,0 +1,98 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Fingerprint identifies the binary and environment a command was started
+// with. It is captured at start if WithFingerprint is set.
+type Fingerprint struct {
+	// Path is the resolved path of the executed binary.
+	Path string
+
+	// SHA256 is the hex encoded checksum of the binary. It is empty if the
+	// binary could not be read.
+	SHA256 string
+
+	// Env is the effective environment of the process with the values of
+	// sensitive variables redacted.
+	Env []string
+}
+
+// redacted replaces redactable values.
+const redacted = "***"
+
+// sensitiveEnvKeys are substrings of environment variable names whose values
+// are redacted in a Fingerprint.
+var sensitiveEnvKeys = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "AUTH"}
+
+// WithFingerprint captures the resolved binary path, its checksum and the
+// effective (redacted) environment at start. It is available on the final
+// State.
+func WithFingerprint() Option {
+
+	return func(c *Command) error {
+		c.record("WithFingerprint")
+		c.fingerprint = true
+		return nil
+	}
+}
+
+func newFingerprint(path string, env []string) *Fingerprint {
+	fp := &Fingerprint{Path: path, Env: redactEnv(env)}
+	if sum, err := checksum(path); err == nil {
+		fp.SHA256 = sum
+	}
+	return fp
+}
+
+// captureFingerprint resolves the binary from the command name rather than
+// the configured cmd.Path, which configurers such as WithReadOnlyPaths may
+// have rewritten to a wrapper.
+func (c *Command) captureFingerprint() *Fingerprint {
+	env := os.Environ()
+	if cmd, ok := c.cmd.(*exec.Cmd); ok && cmd.Env != nil {
+		env = cmd.Env
+	}
+	return newFingerprint(c.ResolvedPath(), c.secrets.redactEnvValues(env))
+}
+
+func checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func redactEnv(env []string) []string {
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		k := envKey(kv)
+		if isSensitiveEnvKey(k) {
+			kv = k + "=" + redacted
+		}
+		out = append(out, kv)
+	}
+	return out
+}
+
+func isSensitiveEnvKey(key string) bool {
+	key = strings.ToUpper(key)
+	for _, s := range sensitiveEnvKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
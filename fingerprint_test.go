@@ -0,0 +1,98 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRedactEnv(t *testing.T) {
+	testCases := []struct {
+		name   string
+		env    []string
+		expect []string
+	}{
+		{name: "plain", env: []string{"HOME=/root", "LANG=C"}, expect: []string{"HOME=/root", "LANG=C"}},
+		{name: "sensitive", env: []string{"GITHUB_TOKEN=abc", "db_password=x", "AWS_SECRET_ACCESS_KEY=y"}, expect: []string{"GITHUB_TOKEN=***", "db_password=***", "AWS_SECRET_ACCESS_KEY=***"}},
+		{name: "noValue", env: []string{"API_KEY"}, expect: []string{"API_KEY=***"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			validateResult(tt, tc.expect, redactEnv(tc.env))
+		})
+	}
+}
+
+func TestCommandFingerprint(t *testing.T) {
+	path, err := exec.LookPath("sh")
+	validateError(t, nil, err)
+	sum, err := checksum(path)
+	validateError(t, nil, err)
+
+	testCases := []struct {
+		name   string
+		args   []interface{}
+		expect *Fingerprint
+	}{
+		{name: "exec", args: []interface{}{WithFingerprint()}, expect: &Fingerprint{Path: path, SHA256: sum}},
+		{name: "service", args: []interface{}{WithFingerprint(), withCommandService(&CommandServiceMock{})}, expect: &Fingerprint{Path: path, SHA256: sum}},
+		{name: "disabled", args: []interface{}{withCommandService(&CommandServiceMock{})}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append(tc.args, "-c", "exit")...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			for range events {
+			}
+			got := (<-cmd.Wait()).Fingerprint()
+			if tc.expect == nil {
+				validateResult(tt, tc.expect, got)
+				return
+			}
+			validateResult(tt, tc.expect.Path, got.Path)
+			validateResult(tt, tc.expect.SHA256, got.SHA256)
+			validateBool(tt, true, len(got.Env) > 0)
+		})
+	}
+}
+
+func TestCaptureFingerprint(t *testing.T) {
+	path, err := exec.LookPath("true")
+	validateError(t, nil, err)
+	sum, err := checksum(path)
+	validateError(t, nil, err)
+
+	t.Run("wrapped", func(tt *testing.T) {
+		cmd, err := NewCommand(context.Background(), "true", WithReadOnlyPaths("/tmp"), WithFingerprint())
+		if errors.Is(err, ErrUnsupported) {
+			tt.Skip(err)
+		}
+		validateError(tt, nil, err)
+		got := cmd.captureFingerprint()
+		validateResult(tt, path, got.Path)
+		validateResult(tt, sum, got.SHA256)
+	})
+	t.Run("serviceSecrets", func(tt *testing.T) {
+		tt.Setenv("FINGERPRINT_TEST_VALUE", "s3cr3t")
+		cmd, err := NewCommand(context.Background(), "true", WithFingerprint(), withCommandService(&CommandServiceMock{}))
+		validateError(tt, nil, err)
+		cmd.secrets.add("s3cr3t")
+		got := cmd.captureFingerprint()
+		validateResult(tt, path, got.Path)
+		found := false
+		for _, kv := range got.Env {
+			if strings.HasPrefix(kv, "FINGERPRINT_TEST_VALUE=") {
+				found = true
+				validateResult(tt, "FINGERPRINT_TEST_VALUE="+redacted, kv)
+			}
+		}
+		validateBool(tt, true, found)
+	})
+}
@@ -1,5 +1,5 @@
 module github.com/shebang-go/command
 
-go 1.14
+go 1.20
 
 require github.com/spf13/cobra v1.1.1
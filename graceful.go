@@ -0,0 +1,135 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// WithGracefulStop changes how the command is stopped when its context is
+// done. Instead of killing the process right away, sig is sent first and the
+// process is killed only if it has not exited after the grace period. Output
+// written during the grace period is not forwarded.
+func WithGracefulStop(sig os.Signal, grace time.Duration) Option {
+
+	return func(c *Command) error {
+		c.record("WithGracefulStop", sig, grace)
+		if sig == nil {
+			return fmt.Errorf("signal cannot be nil")
+		}
+		if grace < 0 {
+			return fmt.Errorf("invalid grace period: %v", grace)
+		}
+		c.stopSignal = sig
+		c.stopGrace = grace
+		return nil
+	}
+}
+
+// WithCancelFunc sets the function which stops the process when the
+// context of the command is done (see exec.Cmd.Cancel). It takes precedence
+// over WithGracefulStop and WithProcessGroup. If fn returns an error other
+// than os.ErrProcessDone, the final State reports it.
+func WithCancelFunc(fn func(*os.Process) error) Option {
+
+	return func(c *Command) error {
+		c.record("WithCancelFunc", funcValue)
+		c.cancelFunc = fn
+		return nil
+	}
+}
+
+// WithWaitDelay bounds the time the command waits for its output after the
+// context is done or, on Linux, after the process exited (see
+// exec.Cmd.WaitDelay). Once the delay has elapsed the process is killed and
+// the output pipes are closed, so Wait returns even if grandchildren keep
+// the pipes open. Output which has not been read by then is lost.
+func WithWaitDelay(d time.Duration) Option {
+
+	return func(c *Command) error {
+		c.record("WithWaitDelay", d)
+		if d < 0 {
+			return fmt.Errorf("invalid wait delay: %v", d)
+		}
+		c.waitDelay = d
+		return nil
+	}
+}
+
+// newExecCmd returns the exec.Cmd for the command.
+func (c *Command) newExecCmd() *exec.Cmd {
+	cmd := exec.CommandContext(c.ctx, c.name, c.args...)
+	if cancel := c.cancel(cmd); cancel != nil {
+		cmd.Cancel = cancel
+	}
+	cmd.WaitDelay = c.waitDelay
+	return cmd
+}
+
+// cancel returns the function exec calls to stop cmd once the context is
+// done, or nil to kill the process.
+func (c *Command) cancel(cmd *exec.Cmd) func() error {
+	switch {
+	case c.cancelFunc != nil:
+		return func() error { return c.cancelFunc(cmd.Process) }
+	case c.stopSignal != nil:
+		return func() error {
+			go c.killAfter(cmd, c.stopGrace)
+			return c.signal(cmd, c.stopSignal)
+		}
+	case c.processGroup:
+		return func() error { return c.kill(cmd) }
+	}
+	return nil
+}
+
+// killAfter kills cmd if it is still running after d.
+func (c *Command) killAfter(cmd *exec.Cmd, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-c.processDone:
+	case <-timer.C:
+		c.kill(cmd)
+	}
+}
+
+// watchExit tracks the exit of the started process. With WithWaitDelay the
+// pipes are closed once the delay has elapsed after the context is done or
+// the process exited.
+func (c *Command) watchExit(pipes ...io.Closer) {
+	done := c.processDone
+	c.cleanups = append(c.cleanups, func() { close(done) })
+	if c.waitDelay <= 0 {
+		return
+	}
+	exited := make(chan struct{})
+	if pid := c.Pid(); pid != 0 {
+		go func() {
+			if waitExited(pid) == nil {
+				close(exited)
+			}
+		}()
+	}
+	go func() {
+		select {
+		case <-c.readDone:
+			return
+		case <-done:
+			return
+		case <-c.ctx.Done():
+		case <-exited:
+		}
+		timer := time.NewTimer(c.waitDelay)
+		defer timer.Stop()
+		select {
+		case <-c.readDone:
+		case <-timer.C:
+			for _, p := range pipes {
+				p.Close()
+			}
+		}
+	}()
+}
@@ -0,0 +1,124 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCommandGracefulStop(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []interface{}
+		script   string
+		exitCode int
+		err      error
+	}{
+		{
+			name:     "handled",
+			args:     []interface{}{WithGracefulStop(syscall.SIGTERM, 5*time.Second)},
+			script:   "trap 'kill $!; exit 3' TERM; echo ready; sleep 10 >/dev/null 2>&1 & wait",
+			exitCode: 3,
+		},
+		{
+			name:     "ignored",
+			args:     []interface{}{WithGracefulStop(syscall.SIGTERM, 2*time.Millisecond)},
+			script:   "trap '' TERM; echo ready; exec sleep 10",
+			exitCode: -1,
+		},
+		{
+			name:     "kill",
+			script:   "echo ready; exec sleep 10",
+			exitCode: -1,
+		},
+		{
+			name: "invalidGrace",
+			args: []interface{}{WithGracefulStop(os.Interrupt, -time.Second)},
+			err:  errors.New("invalid grace period: -1s"),
+		},
+		{
+			name: "nilSignal",
+			args: []interface{}{WithGracefulStop(nil, time.Second)},
+			err:  errors.New("signal cannot be nil"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cmd, err := NewCommand(ctx, "sh", append([]interface{}{"-c", tc.script, WithStreaming()}, tc.args...)...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			<-events
+			cancel()
+			for range events {
+			}
+			state := <-cmd.Wait()
+			validateResult(tt, tc.exitCode, state.ExitCode())
+			validateResult(tt, ReasonCanceled, state.CancelReason())
+		})
+	}
+}
+
+func TestCommandCancelFunc(t *testing.T) {
+	called := false
+	cancelFunc := func(p *os.Process) error {
+		called = true
+		return p.Signal(syscall.SIGTERM)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd, err := NewCommand(ctx, "sh", "-c", "trap 'kill $!; exit 3' TERM; echo ready; sleep 10 >/dev/null 2>&1 & wait", WithStreaming(), WithCancelFunc(cancelFunc))
+	validateError(t, nil, err)
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	<-events
+	cancel()
+	for range events {
+	}
+	state := <-cmd.Wait()
+	validateBool(t, true, called)
+	validateResult(t, 3, state.ExitCode())
+}
+
+func TestCommandWaitDelay(t *testing.T) {
+	testCases := []struct {
+		name  string
+		delay time.Duration
+		err   error
+	}{
+		{name: "delay", delay: time.Millisecond},
+		{name: "invalid", delay: -time.Second, err: errors.New("invalid wait delay: -1s")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			// the orphaned sleep keeps the output pipes open
+			cmd, err := NewCommand(ctx, "sh", "-c", "sleep 3 & echo ready; wait", WithStreaming(), WithWaitDelay(tc.delay))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			<-events
+			started := time.Now()
+			cancel()
+			for range events {
+			}
+			<-cmd.Wait()
+			validateBool(tt, true, time.Since(started) < 2*time.Second)
+		})
+	}
+}
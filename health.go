@@ -0,0 +1,112 @@
+package command
+
+import (
+	"context"
+	"time"
+)
+
+// Health is the health status reported by HealthCheck.
+type Health int
+
+// Health states. A check starts in HealthUnknown.
+const (
+	HealthUnknown Health = iota
+	Healthy
+	Unhealthy
+)
+
+func (h Health) String() string {
+	switch h {
+	case Healthy:
+		return "healthy"
+	case Unhealthy:
+		return "unhealthy"
+	}
+	return "unknown"
+}
+
+// HealthTransition reports a change of the health status.
+type HealthTransition struct {
+	From Health
+	To   Health
+	Time time.Time
+
+	// Result is the result of the check which caused the transition. It is
+	// nil if the command could not be executed.
+	Result *Result
+
+	// Err is the error of the check which caused the transition, if any.
+	Err error
+}
+
+// HealthCheck executes spec every interval and reports health transitions on
+// the returned channel. The status changes to Healthy after successThreshold
+// consecutive successful checks and to Unhealthy after failureThreshold
+// consecutive failed checks. A check fails if it cannot be executed, exits
+// with an error or does not complete within interval. The channel is closed
+// when ctx is done.
+func HealthCheck(ctx context.Context, spec Spec, interval time.Duration, successThreshold, failureThreshold int) <-chan HealthTransition {
+	transitions := make(chan HealthTransition)
+	if successThreshold < 1 {
+		successThreshold = 1
+	}
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	go func() {
+		defer close(transitions)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		status := HealthUnknown
+		successes, failures := 0, 0
+		for {
+			result, err := check(ctx, spec, interval)
+			if ctx.Err() != nil {
+				return
+			}
+			next := status
+			if err == nil {
+				successes, failures = successes+1, 0
+				if successes >= successThreshold {
+					next = Healthy
+				}
+			} else {
+				successes, failures = 0, failures+1
+				if failures >= failureThreshold {
+					next = Unhealthy
+				}
+			}
+			if next != status {
+				t := HealthTransition{From: status, To: next, Time: time.Now(), Result: result, Err: err}
+				status = next
+				select {
+				case <-ctx.Done():
+					return
+				case transitions <- t:
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return transitions
+}
+
+// check executes a single health check bounded by timeout.
+func check(ctx context.Context, spec Spec, timeout time.Duration) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := run(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, result.State.Error()
+}
@@ -0,0 +1,44 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHealthCheck(t *testing.T) {
+	testCases := []struct {
+		name             string
+		spec             Spec
+		successThreshold int
+		failureThreshold int
+		expect           []Health
+	}{
+		{name: "healthy", spec: Spec{Name: "sh", Args: []interface{}{withCommandService(&CommandServiceMock{})}}, successThreshold: 2, failureThreshold: 1, expect: []Health{Healthy}},
+		{name: "unhealthy", spec: Spec{Name: "sh", Args: []interface{}{"-c", "exit 1"}}, successThreshold: 1, failureThreshold: 2, expect: []Health{Unhealthy}},
+		{name: "startError", spec: Spec{Name: "sh", Args: []interface{}{withCommandService(&CommandServiceMock{errStart: true})}}, expect: []Health{Unhealthy}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			transitions := HealthCheck(ctx, tc.spec, 5*time.Millisecond, tc.successThreshold, tc.failureThreshold)
+			got := []Health{}
+			for tr := range transitions {
+				validateResult(tt, HealthUnknown, tr.From)
+				got = append(got, tr.To)
+				cancel()
+			}
+			validateResult(tt, tc.expect, got)
+		})
+	}
+}
+
+func TestHealthString(t *testing.T) {
+	validateResult(t, "unknown", HealthUnknown.String())
+	validateResult(t, "healthy", Healthy.String())
+	validateResult(t, "unhealthy", Unhealthy.String())
+}
@@ -3,6 +3,7 @@
 package command
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"log"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -76,6 +78,31 @@ func createTestContext(tm time.Duration) (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), tm)
 }
 
+// expiringContext reaches its deadline when expire is called, so deadline
+// handling can be tested without waiting for a timer.
+type expiringContext struct {
+	context.Context
+	done chan struct{}
+	once sync.Once
+}
+
+func newExpiringContext() *expiringContext {
+	return &expiringContext{Context: context.Background(), done: make(chan struct{})}
+}
+
+func (c *expiringContext) Done() <-chan struct{} { return c.done }
+
+func (c *expiringContext) Err() error {
+	select {
+	case <-c.done:
+		return context.DeadlineExceeded
+	default:
+		return nil
+	}
+}
+
+func (c *expiringContext) expire() { c.once.Do(func() { close(c.done) }) }
+
 func createTestCommand(ctx context.Context, name string, args ...interface{}) *Command {
 
 	cmd, err := NewCommand(ctx, "bash", args...)
@@ -115,12 +142,14 @@ func (sc *shellScript) CounterLoop(sleep float32, count int, exit int) *shellScr
 }
 
 type CommandServiceMock struct {
+	errStdinPipe  bool
 	errStdoutPipe bool
 	errStderrPipe bool
 	errStart      bool
 	errWait       bool
 	stdout        string
 	stderr        string
+	stdin         bytes.Buffer
 }
 
 func (m *CommandServiceMock) Start() error {
@@ -137,6 +166,19 @@ func (m *CommandServiceMock) Wait() error {
 	return nil
 }
 
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func (m *CommandServiceMock) StdinPipe() (io.WriteCloser, error) {
+	if m.errStdinPipe {
+		return nil, errors.New("errStdinPipe")
+	}
+	return nopWriteCloser{&m.stdin}, nil
+}
+
 func (m *CommandServiceMock) StdoutPipe() (io.ReadCloser, error) {
 	if m.errStdoutPipe {
 		return nil, errors.New("errStdoutPipe")
@@ -186,3 +228,9 @@ func (m *CommandServiceMock) StderrPipe() (io.ReadCloser, error) {
 // 		})
 // 	}
 // }
+
+type processStateMock struct {
+	exit int
+}
+
+func (m *processStateMock) ExitCode() int { return m.exit }
@@ -0,0 +1,55 @@
+package command
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// commandIDs generates the default command IDs.
+var commandIDs atomic.Uint64
+
+// WithID sets the ID of the command, which is attached to every event. By
+// default every command gets a unique ID of the form "cmd-N".
+func WithID(id string) Option {
+
+	return func(c *Command) error {
+		c.record("WithID", id)
+		if len(id) == 0 {
+			return fmt.Errorf("id cannot be empty")
+		}
+		c.id = id
+		return nil
+	}
+}
+
+// ID returns the ID of the command (see WithID).
+func (c *Command) ID() string {
+	return c.id
+}
+
+// Name returns the name of the command.
+func (c *Command) Name() string {
+	return c.name
+}
+
+// CommandID returns the ID of the command which emitted the event.
+func (evt *commandEvent) CommandID() string {
+	return evt.id
+}
+
+// CommandName returns the name of the command which emitted the event.
+func (evt *commandEvent) CommandName() string {
+	return evt.name
+}
+
+// Stream returns StreamStdout or StreamStderr for output events, and an
+// empty string for all other events.
+func (evt *commandEvent) Stream() string {
+	switch evt.Kind() {
+	case EventStdout:
+		return StreamStdout
+	case EventStderr:
+		return StreamStderr
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []interface{}
+		id      string
+		streams []string
+		err     error
+	}{
+		{
+			name:    "default",
+			args:    []interface{}{WithStreaming()},
+			streams: []string{StreamStderr, StreamStdout},
+		},
+		{
+			name:    "withID",
+			args:    []interface{}{WithStreaming(), WithID("build")},
+			id:      "build",
+			streams: []string{StreamStderr, StreamStdout},
+		},
+		{name: "result", id: "result", args: []interface{}{WithID("result")}, streams: []string{""}},
+		{name: "emptyID", args: []interface{}{WithID("")}, err: errors.New("id cannot be empty")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append([]interface{}{withCommandService(&CommandServiceMock{stdout: "1", stderr: "2"})}, tc.args...)...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			if tc.id == "" {
+				validateBool(tt, true, strings.HasPrefix(cmd.ID(), "cmd-"))
+			} else {
+				validateResult(tt, tc.id, cmd.ID())
+			}
+			validateResult(tt, "sh", cmd.Name())
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			streams := []string{}
+			for event := range events {
+				validateResult(tt, cmd.ID(), event.CommandID())
+				validateResult(tt, "sh", event.CommandName())
+				streams = append(streams, event.Stream())
+			}
+			// the streams are read concurrently
+			sort.Strings(streams)
+			validateResult(tt, tc.streams, streams)
+		})
+	}
+}
+
+func TestIdentityUnique(t *testing.T) {
+	cmd1, err := NewCommand(context.Background(), "true")
+	validateError(t, nil, err)
+	cmd2, err := NewCommand(context.Background(), "true")
+	validateError(t, nil, err)
+	validateBool(t, false, cmd1.ID() == cmd2.ID())
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// ErrUnsupported is returned by options which are not supported on the
+// current platform.
+var ErrUnsupported = newCodedError("UNSUPPORTED", "not supported on this platform")
+
+// WithNoNetwork runs the command in an empty network namespace, so it cannot
+// reach the network. It is only supported on Linux; NewCommand fails on
+// other platforms. If the caller is not root a user namespace is created as
+// well.
+func WithNoNetwork() Option {
+
+	return func(c *Command) error {
+		c.record("WithNoNetwork")
+		c.configurers = append(c.configurers, func(cmd *exec.Cmd) error {
+			return isolateNetwork(sysProcAttr(cmd))
+		})
+		return nil
+	}
+}
+
+// sysProcAttr returns the SysProcAttr of cmd, allocating it if necessary.
+func sysProcAttr(cmd *exec.Cmd) *syscall.SysProcAttr {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	return cmd.SysProcAttr
+}
+
+// WithReadOnlyPaths makes paths read-only for the command using a private
+// mount namespace. The host's mounts are not affected. It is only supported
+// on Linux and requires the mount binary; the command is started through
+// sh, which performs the mounts and then executes the command.
+func WithReadOnlyPaths(paths ...string) Option {
+
+	return func(c *Command) error {
+		c.record("WithReadOnlyPaths", paths)
+		c.readOnlyPaths = append(c.readOnlyPaths, paths...)
+		c.addMountConfigurer()
+		return nil
+	}
+}
+
+// WithMaskedPaths hides paths from the command using a private mount
+// namespace: directories appear empty and files appear as /dev/null. See
+// WithReadOnlyPaths for the requirements.
+func WithMaskedPaths(paths ...string) Option {
+
+	return func(c *Command) error {
+		c.record("WithMaskedPaths", paths)
+		c.maskedPaths = append(c.maskedPaths, paths...)
+		c.addMountConfigurer()
+		return nil
+	}
+}
+
+// addMountConfigurer registers the mount namespace setup once.
+func (c *Command) addMountConfigurer() {
+	if c.mountNamespace {
+		return
+	}
+	c.mountNamespace = true
+	c.configurers = append(c.configurers, func(cmd *exec.Cmd) error {
+		return isolateMounts(cmd, c.readOnlyPaths, c.maskedPaths)
+	})
+}
+
+// WithIsolatedHome points HOME and the XDG base directories at a temporary
+// directory, so wrapped tools can neither read nor pollute the caller's
+// dotfiles and credential caches. The directory is created for every
+// execution and removed after the command exited.
+func WithIsolatedHome() Option {
+
+	return func(c *Command) error {
+		c.record("WithIsolatedHome")
+		c.envFuncs = append(c.envFuncs, func(context.Context) (map[string]string, error) {
+			home, err := ioutil.TempDir("", "command-home")
+			if err != nil {
+				return nil, err
+			}
+			c.cleanups = append(c.cleanups, func() { os.RemoveAll(home) })
+			return map[string]string{
+				"HOME":            home,
+				"XDG_CONFIG_HOME": filepath.Join(home, ".config"),
+				"XDG_CACHE_HOME":  filepath.Join(home, ".cache"),
+				"XDG_DATA_HOME":   filepath.Join(home, ".local", "share"),
+				"XDG_STATE_HOME":  filepath.Join(home, ".local", "state"),
+			}, nil
+		})
+		return nil
+	}
+}
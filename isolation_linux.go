@@ -0,0 +1,64 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+func isolateNetwork(attr *syscall.SysProcAttr) error {
+	attr.Cloneflags |= syscall.CLONE_NEWNET
+	if os.Geteuid() != 0 {
+		userNamespace(attr)
+	}
+	return nil
+}
+
+// userNamespace runs the process in a new user namespace which maps the
+// caller's user and group, so unprivileged callers can create the other
+// namespaces.
+func userNamespace(attr *syscall.SysProcAttr) {
+	if attr.Cloneflags&syscall.CLONE_NEWUSER != 0 {
+		return
+	}
+	attr.Cloneflags |= syscall.CLONE_NEWUSER
+	attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+	attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+}
+
+// isolateMounts wraps cmd in a shell which bind mounts readOnly paths
+// read-only and covers masked paths in a private mount namespace before
+// executing the original command.
+func isolateMounts(cmd *exec.Cmd, readOnly, masked []string) error {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		return err
+	}
+	var script strings.Builder
+	script.WriteString("set -e\n")
+	for _, p := range readOnly {
+		q := shellQuote(p)
+		fmt.Fprintf(&script, "mount --bind %s %s\nmount -o remount,bind,ro %s\n", q, q, q)
+	}
+	for _, p := range masked {
+		q := shellQuote(p)
+		fmt.Fprintf(&script, "if [ -d %s ]; then mount -t tmpfs -o ro,size=0 tmpfs %s; else mount --bind /dev/null %s; fi\n", q, q, q)
+	}
+	script.WriteString(`exec "$@"`)
+
+	attr := sysProcAttr(cmd)
+	attr.Unshareflags |= syscall.CLONE_NEWNS
+	if os.Geteuid() != 0 {
+		userNamespace(attr)
+	}
+	cmd.Args = append([]string{"sh", "-c", script.String(), "sh", cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = sh
+	return nil
+}
+
+// shellQuote quotes s for use in a POSIX shell script.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
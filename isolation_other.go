@@ -0,0 +1,17 @@
+// +build !linux
+
+package command
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+func isolateNetwork(attr *syscall.SysProcAttr) error {
+	return fmt.Errorf("network isolation: %w", ErrUnsupported)
+}
+
+func isolateMounts(cmd *exec.Cmd, readOnly, masked []string) error {
+	return fmt.Errorf("mount isolation: %w", ErrUnsupported)
+}
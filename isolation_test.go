@@ -0,0 +1,94 @@
+// +build !integration
+// +build unit
+// +build linux
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// skipIfNamespacesUnavailable skips tests when the sandbox forbids creating
+// namespaces.
+func skipIfNamespacesUnavailable(t *testing.T, err error) {
+	if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EINVAL) || errors.Is(err, os.ErrPermission) {
+		t.Skipf("namespaces unavailable: %v", err)
+	}
+}
+
+func TestCommandNoNetwork(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   []interface{}
+		expect func([]string) bool
+	}{
+		{name: "isolated", opts: []interface{}{WithNoNetwork()}, expect: func(ifaces []string) bool { return len(ifaces) == 1 && ifaces[0] == "lo" }},
+		{name: "host", expect: func(ifaces []string) bool { return len(ifaces) > 0 }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append(tc.opts, "-c", "tail -n +3 /proc/net/dev | cut -d: -f1")...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			skipIfNamespacesUnavailable(tt, err)
+			validateError(tt, nil, err)
+			ifaces := []string{}
+			for event := range events {
+				for _, line := range event.Data().Stdout() {
+					ifaces = append(ifaces, strings.TrimSpace(line))
+				}
+			}
+			<-cmd.Wait()
+			validateBool(tt, true, tc.expect(ifaces))
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	validateResult(t, `'/tmp/a b'`, shellQuote("/tmp/a b"))
+	validateResult(t, `'it'\''s'`, shellQuote("it's"))
+}
+
+func TestCommandMountIsolation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "isolation")
+	validateError(t, nil, err)
+	defer os.RemoveAll(dir)
+	secret := filepath.Join(dir, "secret")
+	validateError(t, nil, ioutil.WriteFile(secret, []byte("hidden\n"), 0644))
+
+	testCases := []struct {
+		name   string
+		opts   []interface{}
+		script string
+		expect []string
+	}{
+		{name: "readOnly", opts: []interface{}{WithReadOnlyPaths(dir)}, script: "touch $0/new 2>/dev/null || echo denied", expect: []string{"denied"}},
+		{name: "writable", script: "touch $0/new && echo written", expect: []string{"written"}},
+		{name: "maskedFile", opts: []interface{}{WithMaskedPaths(secret)}, script: "cat $0/secret; echo end", expect: []string{"end"}},
+		{name: "maskedDir", opts: []interface{}{WithMaskedPaths(dir)}, script: "ls -A $0; echo end", expect: []string{"end"}},
+		{name: "visible", script: "cat $0/secret", expect: []string{"hidden"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append(tc.opts, "-c", tc.script, dir)...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			skipIfNamespacesUnavailable(tt, err)
+			validateError(tt, nil, err)
+			event := <-events
+			state := <-cmd.Wait()
+			if len(event.Data().Stderr()) > 0 && strings.Contains(event.Data().Stderr()[0], "mount") {
+				tt.Skipf("mounts unavailable: %v", event.Data().Stderr())
+			}
+			validateResult(tt, tc.expect, event.Data().Stdout())
+			validateError(tt, nil, state.Error())
+		})
+	}
+}
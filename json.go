@@ -0,0 +1,94 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ErrDecode is matched by every DecodeError.
+var ErrDecode = newCodedError("DECODE_FAILED", "decode failed")
+
+// DecodeError is the error of a line which could not be decoded (see
+// StreamJSON) or of a document (see Result.Decode).
+type DecodeError struct {
+	// Line is the line which could not be decoded. It is empty for a
+	// document.
+	Line string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Line == "" {
+		return fmt.Sprintf("decode: %v", e.Err)
+	}
+	return fmt.Sprintf("decode %q: %v", e.Line, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+func (e *DecodeError) Is(target error) bool { return target == ErrDecode }
+
+// ErrorCode returns the code of ErrDecode.
+func (e *DecodeError) ErrorCode() string { return ErrorCode(ErrDecode) }
+
+// Typed is a value decoded from the output of a command. Err is set if the
+// event carried an error or the line could not be decoded, in which case it
+// is a *DecodeError.
+type Typed[T any] struct {
+	Value T
+	Err   error
+
+	// Event is the event the value was decoded from.
+	Event Event
+}
+
+// StreamJSON executes cmd in streaming mode and decodes every stdout line
+// as newline-delimited JSON into a T. Empty lines and stderr are skipped,
+// events carrying an error are forwarded with the error. The channel has to
+// be read until it is closed or the context of cmd is done; the final state
+// is available from cmd.Wait as usual.
+func StreamJSON[T any](cmd *Command) (<-chan Typed[T], error) {
+	cmd.stream = true
+	events, err := cmd.Execute()
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan Typed[T])
+	send := func(v Typed[T]) bool {
+		select {
+		case <-cmd.ctx.Done():
+			return false
+		case out <- v:
+			return true
+		}
+	}
+	go func() {
+		defer close(out)
+	ForLoop:
+		for event := range events {
+			if event.Error() != nil {
+				if !send(Typed[T]{Err: event.Error(), Event: event}) {
+					break ForLoop
+				}
+				continue
+			}
+			for _, line := range event.Data().Stdout() {
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+				v := Typed[T]{Event: event}
+				if err := json.Unmarshal([]byte(line), &v.Value); err != nil {
+					v.Err = &DecodeError{Line: line, Err: err}
+				}
+				if !send(v) {
+					break ForLoop
+				}
+			}
+		}
+		// the events are drained to clean up the command
+		for range events {
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,85 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type jsonRecord struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+func TestStreamJSON(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    *CommandServiceMock
+		exit    int
+		args    []interface{}
+		values  []jsonRecord
+		errs    []string
+		decodes int
+	}{
+		{
+			name:   "records",
+			mock:   &CommandServiceMock{stdout: "{\"name\":\"a\",\"size\":1}\n\n{\"name\":\"b\",\"size\":2}\n", stderr: "ignored\n"},
+			values: []jsonRecord{{Name: "a", Size: 1}, {Name: "b", Size: 2}},
+			errs:   []string{},
+		},
+		{
+			name:    "decodeError",
+			mock:    &CommandServiceMock{stdout: "{\"name\":\"a\"}\nnot json\n"},
+			values:  []jsonRecord{{Name: "a"}, {}},
+			errs:    []string{`decode "not json": invalid character 'o' in literal null (expecting 'u')`},
+			decodes: 1,
+		},
+		{
+			name:   "exitError",
+			mock:   &CommandServiceMock{stdout: "{\"size\":3}\n", errWait: true},
+			exit:   2,
+			args:   []interface{}{WithErrOnNonZeroExit()},
+			values: []jsonRecord{{Size: 3}, {}},
+			errs:   []string{"exit code 2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append([]interface{}{withCommandService(tc.mock)}, tc.args...)...)
+			validateError(tt, nil, err)
+			cmd.processState = &processStateMock{exit: tc.exit}
+			records, err := StreamJSON[jsonRecord](cmd)
+			validateError(tt, nil, err)
+			values := []jsonRecord{}
+			errs := []string{}
+			decodes := 0
+			for record := range records {
+				values = append(values, record.Value)
+				if record.Err != nil {
+					errs = append(errs, record.Err.Error())
+					if errors.Is(record.Err, ErrDecode) {
+						decodes++
+						validateResult(tt, "DECODE_FAILED", ErrorCode(record.Err))
+					}
+				}
+			}
+			validateResult(tt, tc.values, values)
+			validateResult(tt, tc.errs, errs)
+			validateResult(tt, tc.decodes, decodes)
+			<-cmd.Wait()
+		})
+	}
+}
+
+func TestStreamJSONExecuteError(t *testing.T) {
+	cmd, err := NewCommand(context.Background(), "true")
+	validateError(t, nil, err)
+	_, err = cmd.Execute()
+	validateError(t, nil, err)
+	cmd.Drain(context.Background())
+	_, err = StreamJSON[jsonRecord](cmd)
+	validateBool(t, true, err != nil)
+}
@@ -0,0 +1,99 @@
+package command
+
+import "fmt"
+
+// EventKind identifies the kind of an event.
+type EventKind int
+
+const (
+	// EventStdout is an event carrying stdout output in streaming mode.
+	EventStdout EventKind = iota
+
+	// EventStderr is an event carrying stderr output in streaming mode.
+	EventStderr
+
+	// EventError is an event carrying an error but no output, e.g. a
+	// cancellation or a read error.
+	EventError
+
+	// EventResult is the event carrying the collected output in
+	// non-streaming mode.
+	EventResult
+
+	// EventStarted is the first event if WithLifecycleEvents is set.
+	EventStarted
+
+	// EventExited is the last event if WithLifecycleEvents is set. It
+	// carries the final State.
+	EventExited
+
+	// EventMatch is an event carrying the match of a matcher (see
+	// WithMatcher).
+	EventMatch
+
+	// EventProgress is an event carrying the progress extracted from a line
+	// (see WithProgressParsers).
+	EventProgress
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventStdout:
+		return "stdout"
+	case EventStderr:
+		return "stderr"
+	case EventError:
+		return "error"
+	case EventResult:
+		return "result"
+	case EventStarted:
+		return "started"
+	case EventExited:
+		return "exited"
+	case EventMatch:
+		return "match"
+	case EventProgress:
+		return "progress"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// WithLifecycleEvents emits an EventStarted event before the output and an
+// EventExited event carrying the final State after all other events, so
+// that a single read loop can handle the whole execution without Wait.
+// In non-streaming mode, the error of the result event is nil instead of
+// "no error" if the execution didn't fail.
+func WithLifecycleEvents() Option {
+
+	return func(c *Command) error {
+		c.record("WithLifecycleEvents")
+		c.lifecycle = true
+		return nil
+	}
+}
+
+// Kind returns the kind of the event.
+func (evt *commandEvent) Kind() EventKind {
+	switch {
+	case evt.kind != EventStdout:
+		return evt.kind
+	case evt.err != nil:
+		return EventError
+	case len(evt.data.Stderr()) > 0:
+		return EventStderr
+	}
+	return EventStdout
+}
+
+// State returns the final state carried by an EventExited event, or nil.
+func (evt *commandEvent) State() State {
+	return evt.state
+}
+
+// lifecycleEvent returns an event of kind without output.
+func (c *Command) lifecycleEvent(kind EventKind, state State) *commandEvent {
+	evt := c.newEvent(newCommandResult([]string{}, []string{}), nil)
+	evt.kind = kind
+	evt.state = state
+	return evt
+}
@@ -0,0 +1,80 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEventKinds(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      []interface{}
+		mock      *CommandServiceMock
+		kinds     []EventKind
+		errs      []bool
+		exitCode  int
+		lifecycle bool
+	}{
+		{
+			name:  "stream",
+			args:  []interface{}{WithStreaming(), WithErrOnNonZeroExit()},
+			mock:  &CommandServiceMock{stdout: "1", errWait: true},
+			kinds: []EventKind{EventStdout, EventError},
+			errs:  []bool{false, true},
+		},
+		{
+			name:  "streamStderr",
+			args:  []interface{}{WithStreaming(), WithErrOnNonZeroExit()},
+			mock:  &CommandServiceMock{stderr: "2", errWait: true},
+			kinds: []EventKind{EventStderr, EventError},
+			errs:  []bool{false, true},
+		},
+		{name: "result", mock: &CommandServiceMock{stdout: "1", stderr: "2", errWait: true}, kinds: []EventKind{EventResult}, errs: []bool{true}},
+		{
+			name:      "streamLifecycle",
+			args:      []interface{}{WithStreaming(), WithLifecycleEvents()},
+			mock:      &CommandServiceMock{stdout: "1", errWait: true},
+			kinds:     []EventKind{EventStarted, EventStdout, EventExited},
+			errs:      []bool{false, false, false},
+			exitCode:  3,
+			lifecycle: true,
+		},
+		{
+			name:      "resultLifecycle",
+			args:      []interface{}{WithLifecycleEvents()},
+			mock:      &CommandServiceMock{stdout: "1", stderr: "2", errWait: true},
+			kinds:     []EventKind{EventStarted, EventResult, EventExited},
+			errs:      []bool{false, false, false},
+			exitCode:  3,
+			lifecycle: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append([]interface{}{withCommandService(tc.mock)}, tc.args...)...)
+			validateError(tt, nil, err)
+			cmd.processState = &processStateMock{exit: 3}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			kinds := []EventKind{}
+			errs := []bool{}
+			var state State
+			for event := range events {
+				kinds = append(kinds, event.Kind())
+				errs = append(errs, event.Error() != nil)
+				if event.Kind() == EventExited {
+					state = event.State()
+				}
+			}
+			validateResult(tt, tc.kinds, kinds)
+			validateResult(tt, tc.errs, errs)
+			if tc.lifecycle {
+				validateResult(tt, tc.exitCode, state.ExitCode())
+				validateResult(tt, state, <-cmd.Wait())
+			}
+		})
+	}
+}
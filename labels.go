@@ -0,0 +1,41 @@
+package command
+
+import (
+	"context"
+	"time"
+)
+
+// WithContextLabels extracts labels from the command's context using fn. The
+// labels are attached to every event and to the final State, so request
+// scoped values like a user or trace ID can be correlated with the output.
+// The option can be used multiple times, later labels override earlier ones.
+func WithContextLabels(fn func(ctx context.Context) map[string]string) Option {
+
+	return func(c *Command) error {
+		c.record("WithContextLabels", funcValue)
+		labels := fn(c.ctx)
+		if len(labels) == 0 {
+			return nil
+		}
+		if c.labels == nil {
+			c.labels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			c.labels[k] = v
+		}
+		return nil
+	}
+}
+
+// newEvent returns an event carrying the command's identity, labels and
+// process ID.
+func (c *Command) newEvent(data Data, err error) *commandEvent {
+	evt := newCommandEvent(data, err)
+	evt.id = c.id
+	evt.name = c.name
+	evt.labels = c.labels
+	evt.pid = c.Pid()
+	evt.seq = c.seq.Add(1)
+	evt.time = time.Now()
+	return evt
+}
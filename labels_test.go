@@ -0,0 +1,47 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+)
+
+type labelKey struct{}
+
+func TestCommandContextLabels(t *testing.T) {
+	fromCtx := func(ctx context.Context) map[string]string {
+		if v, ok := ctx.Value(labelKey{}).(string); ok {
+			return map[string]string{"user": v}
+		}
+		return nil
+	}
+	static := func(context.Context) map[string]string { return map[string]string{"user": "override", "trace": "t1"} }
+
+	testCases := []struct {
+		name   string
+		ctx    context.Context
+		stream bool
+		opts   []interface{}
+		expect map[string]string
+	}{
+		{name: "none", ctx: context.Background()},
+		{name: "noValue", ctx: context.Background(), opts: []interface{}{WithContextLabels(fromCtx)}},
+		{name: "value", ctx: context.WithValue(context.Background(), labelKey{}, "alice"), opts: []interface{}{WithContextLabels(fromCtx)}, expect: map[string]string{"user": "alice"}},
+		{name: "stream", ctx: context.WithValue(context.Background(), labelKey{}, "alice"), opts: []interface{}{WithContextLabels(fromCtx), WithStreaming()}, expect: map[string]string{"user": "alice"}},
+		{name: "merge", ctx: context.WithValue(context.Background(), labelKey{}, "alice"), opts: []interface{}{WithContextLabels(fromCtx), WithContextLabels(static)}, expect: map[string]string{"user": "override", "trace": "t1"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(tc.ctx, "sh", append(tc.opts, withCommandService(&CommandServiceMock{stdout: "out"}))...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			for event := range events {
+				validateResult(tt, tc.expect, event.Labels())
+			}
+			validateResult(tt, tc.expect, (<-cmd.Wait()).Labels())
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// minSegmentSize is the smallest buffer size of bufio.Reader.
+const minSegmentSize = 16
+
+// WithUnboundedLines reads output lines of any length instead of failing on
+// lines exceeding the maximum line size (see WithMaxLineSize). In
+// streaming mode, lines longer than segmentSize bytes are emitted as
+// several events; all but the last one are marked by Data.Partial. In
+// non-streaming mode the segments are joined. The segment size must be at
+// least 16 bytes.
+func WithUnboundedLines(segmentSize int) Option {
+
+	return func(c *Command) error {
+		c.record("WithUnboundedLines", segmentSize)
+		if segmentSize < minSegmentSize {
+			return fmt.Errorf("invalid segment size: %d", segmentSize)
+		}
+		c.segmentSize = segmentSize
+		return nil
+	}
+}
+
+// readLines reads the lines of inStream in segments of segmentSize bytes.
+// If segmentSize is zero the segments of a line are joined.
+func readLines(ctx context.Context, inStream io.Reader, errStream bool, segmentSize int) <-chan streamData {
+	outStream := make(chan streamData)
+	size := segmentSize
+	if size == 0 {
+		size = 4096
+	}
+	reader := bufio.NewReaderSize(inStream, size)
+
+	go func() {
+		defer close(outStream)
+		var line strings.Builder
+		for {
+			segment, isPrefix, err := reader.ReadLine()
+			if err != nil {
+				if err != io.EOF {
+					event := *newStreamData("", errStream)
+					event.err = newReadError(errStream, err)
+					select {
+					case <-ctx.Done():
+					case outStream <- event:
+					}
+				}
+				return
+			}
+			line.Write(segment)
+			if isPrefix && segmentSize == 0 {
+				continue
+			}
+			event := *newStreamData(line.String(), errStream)
+			event.partial = isPrefix
+			event.at = time.Now()
+			line.Reset()
+			select {
+			case <-ctx.Done():
+				return
+			case outStream <- event:
+			}
+		}
+	}()
+	return outStream
+}
@@ -0,0 +1,65 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUnboundedLines(t *testing.T) {
+	long := strings.Repeat("x", 40) + "\nshort\nlast"
+	testCases := []struct {
+		name     string
+		segment  int
+		output   string
+		stream   bool
+		stdout   []string
+		partials []bool
+		lines    int64
+		err      error
+	}{
+		{
+			name:     "stream",
+			segment:  16,
+			output:   long,
+			stream:   true,
+			stdout:   []string{strings.Repeat("x", 16), strings.Repeat("x", 16), strings.Repeat("x", 8), "short", "last"},
+			partials: []bool{true, true, false, false, false},
+			lines:    3,
+		},
+		{name: "joined", segment: 16, output: long, stdout: []string{strings.Repeat("x", 40), "short", "last"}, lines: 3},
+		{name: "huge", segment: 16, output: strings.Repeat("x", 100000), stdout: []string{strings.Repeat("x", 100000)}, lines: 1},
+		{name: "invalid", segment: 8, err: errors.New("invalid segment size: 8")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := []interface{}{withCommandService(&CommandServiceMock{stdout: tc.output}), WithUnboundedLines(tc.segment)}
+			if tc.stream {
+				args = append(args, WithStreaming())
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			stdout := []string{}
+			partials := []bool{}
+			for event := range events {
+				stdout = append(stdout, event.Data().Stdout()...)
+				partials = append(partials, event.Data().Partial())
+			}
+			state := <-cmd.Wait()
+			validateResult(tt, tc.stdout, stdout)
+			if tc.stream {
+				validateResult(tt, tc.partials, partials)
+			}
+			validateResult(tt, tc.lines, state.Stats().StdoutLines)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Match is a line matched by a matcher (see WithMatcher).
+type Match struct {
+	// Name is the name of the matcher.
+	Name string
+
+	// Line is the matched line.
+	Line string
+
+	// Stream is StreamStdout or StreamStderr.
+	Stream string
+
+	// Groups holds the text of the leftmost match and its submatches, see
+	// regexp.FindStringSubmatch.
+	Groups []string
+
+	// Named maps the names of named submatches to their text.
+	Named map[string]string
+}
+
+// WithMatcher emits an EventMatch event for every line matching re, after
+// the event of the line itself, so readiness lines or errors can be reacted
+// upon without parsing every line. Matchers see every line as it was read,
+// before it is filtered, transformed or decorated. In non-streaming mode,
+// the match events precede the result event. The option can be used
+// multiple times, the match events of a line are emitted in the order of
+// the matchers.
+func WithMatcher(name string, re *regexp.Regexp) Option {
+
+	return func(c *Command) error {
+		c.record("WithMatcher", name, fmt.Sprintf("%v", re))
+		if len(name) == 0 {
+			return fmt.Errorf("matcher name cannot be empty")
+		}
+		if re == nil {
+			return fmt.Errorf("matcher regexp cannot be nil")
+		}
+		c.matchers = append(c.matchers, matcher{name: name, re: re})
+		return nil
+	}
+}
+
+type matcher struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// match returns the matches of the line i. Secrets are redacted: groups
+// overlapping a secret or redaction pattern match in the line are replaced
+// entirely.
+func (c *Command) match(i streamData) []*Match {
+	if i.chunk != nil {
+		return nil
+	}
+	var matches []*Match
+	for _, m := range c.matchers {
+		loc := m.re.FindStringSubmatchIndex(i.data)
+		if loc == nil {
+			continue
+		}
+		match := &Match{Name: m.name, Line: c.secrets.redact(i.data), Stream: StreamStdout, Groups: make([]string, len(loc)/2)}
+		if i.isStderr {
+			match.Stream = StreamStderr
+		}
+		for n := range match.Groups {
+			start, end := loc[2*n], loc[2*n+1]
+			switch {
+			case start < 0:
+			case c.secrets.redacts(i.data, start, end):
+				match.Groups[n] = redacted
+			default:
+				match.Groups[n] = c.secrets.redact(i.data[start:end])
+			}
+		}
+		for n, name := range m.re.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			if match.Named == nil {
+				match.Named = map[string]string{}
+			}
+			match.Named[name] = match.Groups[n]
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// Match returns the match carried by an EventMatch event, or nil.
+func (evt *commandEvent) Match() *Match {
+	return evt.match
+}
@@ -0,0 +1,90 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	listening := regexp.MustCompile(`Listening on (?P<port>\d+)`)
+	failed := regexp.MustCompile(`^error: (.*)$`)
+	testCases := []struct {
+		name    string
+		args    []interface{}
+		mock    *CommandServiceMock
+		kinds   []EventKind
+		matches []Match
+		err     error
+	}{
+		{
+			name:  "stream",
+			args:  []interface{}{WithStreaming(), WithMatcher("ready", listening), WithMatcher("error", failed)},
+			mock:  &CommandServiceMock{stdout: "starting\nListening on 8080"},
+			kinds: []EventKind{EventStdout, EventStdout, EventMatch},
+			matches: []Match{
+				{Name: "ready", Line: "Listening on 8080", Stream: StreamStdout, Groups: []string{"Listening on 8080", "8080"}, Named: map[string]string{"port": "8080"}},
+			},
+		},
+		{
+			name:  "streamStderr",
+			args:  []interface{}{WithStreaming(), WithMatcher("ready", listening), WithMatcher("error", failed)},
+			mock:  &CommandServiceMock{stderr: "error: disk full"},
+			kinds: []EventKind{EventStderr, EventMatch},
+			matches: []Match{
+				{Name: "error", Line: "error: disk full", Stream: StreamStderr, Groups: []string{"error: disk full", "disk full"}},
+			},
+		},
+		{
+			name:  "result",
+			args:  []interface{}{WithMatcher("ready", listening)},
+			kinds: []EventKind{EventMatch, EventResult},
+			matches: []Match{
+				{Name: "ready", Line: "Listening on 8080", Stream: StreamStdout, Groups: []string{"Listening on 8080", "8080"}, Named: map[string]string{"port": "8080"}},
+			},
+		},
+		{
+			name:  "filteredAndBatched",
+			args:  []interface{}{WithStreaming(), WithBatching(10, 0), WithMatcher("ready", listening), WithLineFilter(func(string, bool) bool { return false })},
+			kinds: []EventKind{EventMatch},
+			matches: []Match{
+				{Name: "ready", Line: "Listening on 8080", Stream: StreamStdout, Groups: []string{"Listening on 8080", "8080"}, Named: map[string]string{"port": "8080"}},
+			},
+		},
+		{name: "emptyName", args: []interface{}{WithMatcher("", listening)}, err: errors.New("matcher name cannot be empty")},
+		{name: "nilRegexp", args: []interface{}{WithMatcher("ready", nil)}, err: errors.New("matcher regexp cannot be nil")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			mock := tc.mock
+			if mock == nil {
+				mock = &CommandServiceMock{stdout: "starting\nListening on 8080", stderr: "error: disk full"}
+			}
+			args := append([]interface{}{withCommandService(mock)}, tc.args...)
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			kinds := []EventKind{}
+			matches := []Match{}
+			for event := range events {
+				kinds = append(kinds, event.Kind())
+				if event.Kind() == EventMatch {
+					matches = append(matches, *event.Match())
+					validateResult(tt, 0, len(event.Data().Out()))
+				} else {
+					validateBool(tt, true, event.Match() == nil)
+				}
+			}
+			validateResult(tt, tc.kinds, kinds)
+			validateResult(tt, tc.matches, matches)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is matched by a *NotFoundError.
+var ErrNotFound = newCodedError("NOT_FOUND", "executable not found")
+
+// maxSuggestions limits the executables suggested by a NotFoundError.
+const maxSuggestions = 3
+
+// NotFoundError is returned by Execute, or by NewCommand if WithLookPath is
+// set, if the executable does not exist. It lists the directories which were
+// searched and similarly named executables found in them.
+type NotFoundError struct {
+	Name string
+
+	// Path holds the searched directories.
+	Path []string
+
+	// Suggestions holds the names of the closest matching executables.
+	Suggestions []string
+
+	Err error
+}
+
+func (e *NotFoundError) Error() string {
+	msg := fmt.Sprintf("command %q not found in %s", e.Name, strings.Join(e.Path, string(os.PathListSeparator)))
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(", did you mean %s?", strings.Join(e.Suggestions, ", "))
+	}
+	return msg
+}
+
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// Is reports whether target is ErrNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// WithLookPath resolves the executable when the command is created, so
+// NewCommand fails with a *NotFoundError instead of Execute. See also
+// ResolvedPath.
+func WithLookPath() Option {
+
+	return func(c *Command) error {
+		c.record("WithLookPath")
+		c.lookPath = true
+		return nil
+	}
+}
+
+// resolve looks up the executable and records its path.
+func (c *Command) resolve() error {
+	path, err := exec.LookPath(c.name)
+	if err != nil {
+		return newNotFoundError(c.name, err)
+	}
+	c.resolvedPath = path
+	return nil
+}
+
+// ResolvedPath returns the path of the executable, or an empty string if it
+// cannot be found.
+func (c *Command) ResolvedPath() string {
+	if c.resolvedPath == "" {
+		c.resolvedPath, _ = exec.LookPath(c.name)
+	}
+	return c.resolvedPath
+}
+
+// notFound wraps err in a *NotFoundError if the executable does not exist,
+// otherwise it returns err unchanged.
+func (c *Command) notFound(err error) error {
+	if !errors.Is(err, exec.ErrNotFound) && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return newNotFoundError(c.name, err)
+}
+
+// newNotFoundError returns a *NotFoundError for name wrapping err.
+func newNotFoundError(name string, err error) *NotFoundError {
+	e := &NotFoundError{Name: name, Err: err}
+	if strings.ContainsRune(name, os.PathSeparator) {
+		e.Path = []string{filepath.Dir(name)}
+		name = filepath.Base(name)
+	} else {
+		e.Path = filepath.SplitList(os.Getenv("PATH"))
+	}
+	e.Suggestions = suggestExecutables(name, e.Path)
+	return e
+}
+
+// suggestExecutables returns the executables in dirs whose names are closest
+// to name.
+func suggestExecutables(name string, dirs []string) []string {
+	maxDistance := len(name) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	distances := map[string]int{}
+	for _, dir := range dirs {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if _, ok := distances[entry.Name()]; ok || entry.IsDir() || entry.Mode()&0111 == 0 {
+				continue
+			}
+			if d := editDistance(name, entry.Name()); d <= maxDistance {
+				distances[entry.Name()] = d
+			}
+		}
+	}
+	names := make([]string, 0, len(distances))
+	for n := range distances {
+		names = append(names, n)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if distances[names[i]] != distances[names[j]] {
+			return distances[names[i]] < distances[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxSuggestions {
+		names = names[:maxSuggestions]
+	}
+	return names
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,101 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	testCases := []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "", b: "", expect: 0},
+		{a: "git", b: "", expect: 3},
+		{a: "gti", b: "git", expect: 2},
+		{a: "kubectl", b: "kubctl", expect: 1},
+		{a: "docker", b: "docker", expect: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.a+"/"+tc.b, func(tt *testing.T) {
+			validateResult(tt, tc.expect, editDistance(tc.a, tc.b))
+		})
+	}
+}
+
+func TestCommandNotFound(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notfound")
+	validateError(t, nil, err)
+	defer os.RemoveAll(dir)
+	for name, mode := range map[string]os.FileMode{"kubectl": 0755, "kubecfg": 0755, "kubectx": 0644, "docker": 0755} {
+		validateError(t, nil, ioutil.WriteFile(filepath.Join(dir, name), nil, mode))
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir)
+
+	testCases := []struct {
+		name        string
+		command     string
+		path        []string
+		suggestions []string
+		cause       error
+	}{
+		{name: "lookPath", command: "kubctl", path: []string{dir}, suggestions: []string{"kubectl"}, cause: exec.ErrNotFound},
+		{name: "absolute", command: filepath.Join(dir, "dockr"), path: []string{dir}, suggestions: []string{"docker"}, cause: os.ErrNotExist},
+		{name: "noSuggestion", command: "zzzzzz", path: []string{dir}, suggestions: []string{}, cause: exec.ErrNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), tc.command)
+			validateError(tt, nil, err)
+			_, err = cmd.Execute()
+			var notFound *NotFoundError
+			validateBool(tt, true, errors.As(err, &notFound))
+			validateResult(tt, tc.path, notFound.Path)
+			validateResult(tt, tc.suggestions, notFound.Suggestions)
+			validateBool(tt, true, errors.Is(err, tc.cause))
+		})
+	}
+}
+
+func TestCommandLookPath(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	validateError(t, nil, err)
+	dir, err := ioutil.TempDir("", "lookpath")
+	validateError(t, nil, err)
+	defer os.RemoveAll(dir)
+	missing := filepath.Join(dir, "missing")
+	testCases := []struct {
+		name     string
+		command  string
+		opts     []interface{}
+		resolved string
+		err      bool
+	}{
+		{name: "found", command: "sh", opts: []interface{}{WithLookPath()}, resolved: sh},
+		{name: "missing", command: missing, opts: []interface{}{WithLookPath()}, err: true},
+		{name: "lazy", command: "sh", resolved: sh},
+		{name: "lazyMissing", command: missing},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), tc.command, tc.opts...)
+			validateBool(tt, tc.err, errors.Is(err, ErrNotFound))
+			if err != nil {
+				var notFound *NotFoundError
+				validateBool(tt, true, errors.As(err, &notFound))
+				validateBool(tt, true, len(notFound.Path) > 0)
+				return
+			}
+			validateResult(tt, tc.resolved, cmd.ResolvedPath())
+		})
+	}
+}
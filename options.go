@@ -0,0 +1,50 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OptionInfo describes an option applied to a Command. Values of sensitive
+// environment variables and secrets are redacted and functions are shown
+// as "func".
+type OptionInfo struct {
+	Name  string
+	Value string
+}
+
+// Options returns the options the command was created with, in the order
+// they were applied, e.g. for debugging endpoints and audit logs.
+func (c *Command) Options() []OptionInfo {
+	return append([]OptionInfo{}, c.options...)
+}
+
+// record registers an applied option. The values are formatted with
+// fmt.Sprint and separated by spaces.
+func (c *Command) record(name string, value ...interface{}) {
+	values := make([]string, len(value))
+	for i, v := range value {
+		values[i] = fmt.Sprint(v)
+	}
+	c.options = append(c.options, OptionInfo{Name: name, Value: strings.Join(values, " ")})
+}
+
+// funcValue is recorded for function arguments.
+const funcValue = "func"
+
+// redactedMap formats vars as sorted "key=value" pairs with sensitive values
+// redacted.
+func redactedMap(vars map[string]string) []string {
+	return redactEnv(mergeEnv(nil, vars))
+}
+
+// redactedKeys formats the keys of vars as sorted "key=***" pairs.
+func redactedKeys(vars map[string]string) []string {
+	kvs := make([]string, 0, len(vars))
+	for k := range vars {
+		kvs = append(kvs, k+"="+redacted)
+	}
+	sort.Strings(kvs)
+	return kvs
+}
@@ -0,0 +1,62 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCommandOptions(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   []interface{}
+		expect []OptionInfo
+	}{
+		{name: "none", expect: []OptionInfo{}},
+		{
+			name: "values",
+			opts: []interface{}{
+				WithStreaming(),
+				WithEnv([]string{"A=1", "API_TOKEN=abc"}),
+				WithEnvMap(map[string]string{"DB_PASSWORD": "pw", "B": "2"}),
+				WithInheritEnv(false),
+				WithSuccessExitCodes(1, 2),
+				WithGracefulStop(syscall.SIGTERM, time.Second),
+				WithLineRateLimit(10, 5),
+				WithSecrets(secretProviderMock{}, map[string]string{"PW": "db", "KEY": "api"}),
+				WithCmdCustomizer(func(*exec.Cmd) error { return nil }),
+			},
+			expect: []OptionInfo{
+				{Name: "WithStreaming"},
+				{Name: "WithEnv", Value: "[A=1 API_TOKEN=***]"},
+				{Name: "WithEnvMap", Value: "[B=2 DB_PASSWORD=***]"},
+				{Name: "WithInheritEnv", Value: "false"},
+				{Name: "WithSuccessExitCodes", Value: "[1 2]"},
+				{Name: "WithGracefulStop", Value: "terminated 1s"},
+				{Name: "WithLineRateLimit", Value: "10 lines/s, burst 5"},
+				{Name: "WithSecrets", Value: "[KEY=*** PW=***]"},
+				{Name: "WithCmdCustomizer", Value: "func"},
+			},
+		},
+		{
+			name: "whitespace",
+			opts: []interface{}{WithLinePrefix("> "), WithSpill(10, "")},
+			expect: []OptionInfo{
+				{Name: "WithLinePrefix", Value: "> "},
+				{Name: "WithSpill", Value: "10 "},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", tc.opts...)
+			validateError(tt, nil, err)
+			validateResult(tt, tc.expect, cmd.Options())
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package parsers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shebang-go/command"
+)
+
+// DiskUsage is a line of `df -P` output. Sizes are in the block size of the
+// header, usually 1024 bytes.
+type DiskUsage struct {
+	Filesystem string
+	Blocks     int64
+	Used       int64
+	Available  int64
+
+	// Capacity is the used capacity in percent.
+	Capacity  int
+	MountedOn string
+	Err       error
+}
+
+// DF parses the output of `df -P`. The header line is skipped.
+func DF(events <-chan command.Event) <-chan DiskUsage {
+	out := make(chan DiskUsage)
+	go func() {
+		defer close(out)
+		header := true
+		for line := range lines(events) {
+			if header {
+				header = false
+				continue
+			}
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			out <- parseDF(line)
+		}
+	}()
+	return out
+}
+
+func parseDF(line string) DiskUsage {
+	fields := splitFields(line, 6)
+	if len(fields) != 6 {
+		return DiskUsage{Err: fmt.Errorf("invalid df line: %q", line)}
+	}
+	u := DiskUsage{Filesystem: fields[0], MountedOn: fields[5]}
+	var err error
+	for i, p := range []*int64{&u.Blocks, &u.Used, &u.Available} {
+		if *p, err = strconv.ParseInt(fields[i+1], 10, 64); err != nil {
+			return DiskUsage{Err: fmt.Errorf("invalid df line: %q: %w", line, err)}
+		}
+	}
+	if u.Capacity, err = strconv.Atoi(strings.TrimSuffix(fields[4], "%")); err != nil {
+		return DiskUsage{Err: fmt.Errorf("invalid df line: %q: %w", line, err)}
+	}
+	return u
+}
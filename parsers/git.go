@@ -0,0 +1,48 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shebang-go/command"
+)
+
+// GitStatus is an entry of `git status --porcelain` output.
+type GitStatus struct {
+	// Index and WorkTree are the status codes of the index and the work
+	// tree, e.g. 'M' for modified or '?' for untracked.
+	Index    byte
+	WorkTree byte
+	Path     string
+
+	// OrigPath is the source of a rename or copy.
+	OrigPath string
+	Err      error
+}
+
+// GitPorcelain parses the output of `git status --porcelain` (version 1).
+// Quoted paths are returned as printed by git.
+func GitPorcelain(events <-chan command.Event) <-chan GitStatus {
+	out := make(chan GitStatus)
+	go func() {
+		defer close(out)
+		for line := range lines(events) {
+			if line == "" {
+				continue
+			}
+			out <- parseGitStatus(line)
+		}
+	}()
+	return out
+}
+
+func parseGitStatus(line string) GitStatus {
+	if len(line) < 4 || line[2] != ' ' {
+		return GitStatus{Err: fmt.Errorf("invalid git status line: %q", line)}
+	}
+	s := GitStatus{Index: line[0], WorkTree: line[1], Path: line[3:]}
+	if i := strings.Index(s.Path, " -> "); i >= 0 && (s.Index == 'R' || s.Index == 'C') {
+		s.OrigPath, s.Path = s.Path[:i], s.Path[i+4:]
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package parsers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/shebang-go/command"
+)
+
+// JSONRecord is an object parsed by JSONLines.
+type JSONRecord struct {
+	Value map[string]interface{}
+	Err   error
+}
+
+// JSONLines parses one JSON object per line, as printed by e.g.
+// `docker ps --format '{{json .}}'`. Empty lines are skipped.
+func JSONLines(events <-chan command.Event) <-chan JSONRecord {
+	out := make(chan JSONRecord)
+	go func() {
+		defer close(out)
+		for line := range lines(events) {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			var rec JSONRecord
+			rec.Err = json.Unmarshal([]byte(line), &rec.Value)
+			out <- rec
+		}
+	}()
+	return out
+}
+
+// JSONDocument decodes the complete stdout as a single JSON document into v,
+// as printed by e.g. `kubectl get pods -o json`.
+func JSONDocument(events <-chan command.Event, v interface{}) error {
+	var doc strings.Builder
+	for line := range lines(events) {
+		doc.WriteString(line)
+		doc.WriteByte('\n')
+	}
+	return json.Unmarshal([]byte(doc.String()), v)
+}
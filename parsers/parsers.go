@@ -0,0 +1,54 @@
+// Package parsers turns the events of commands into typed records for the
+// output formats of common tools. Every parser reads the stdout lines of the
+// events until the channel is closed, so it works with both streaming and
+// non-streaming commands. Errors carried by the events are ignored; check
+// the final State of the command instead.
+//
+// The parsers have no cancellation: the returned channels have to be read
+// until they are closed, even if the context of the command is done. A
+// parser whose channel is not read stops reading the events, which blocks
+// the command, and its goroutine leaks.
+package parsers
+
+import (
+	"strings"
+
+	"github.com/shebang-go/command"
+)
+
+// lines forwards the stdout lines of events. The returned channel has to be
+// read until it is closed.
+func lines(events <-chan command.Event) <-chan string {
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		for event := range events {
+			if event.Data() == nil {
+				continue
+			}
+			for _, line := range event.Data().Stdout() {
+				out <- line
+			}
+		}
+	}()
+	return out
+}
+
+// splitFields splits line into at most n whitespace separated fields. The
+// last field holds the rest of the line.
+func splitFields(line string, n int) []string {
+	fields := make([]string, 0, n)
+	line = strings.TrimSpace(line)
+	for len(fields) < n-1 && line != "" {
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			break
+		}
+		fields = append(fields, line[:i])
+		line = strings.TrimLeft(line[i:], " \t")
+	}
+	if line != "" {
+		fields = append(fields, line)
+	}
+	return fields
+}
@@ -0,0 +1,207 @@
+// +build !integration
+// +build unit
+
+package parsers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/shebang-go/command"
+)
+
+func validateResult(t *testing.T, expect, got interface{}) {
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("expected:%v, got:%v", expect, got)
+	}
+}
+
+func validateError(t *testing.T, expect, got error) {
+	if fmt.Sprint(expect) != fmt.Sprint(got) {
+		t.Fatalf("expected:%v, got:%v", expect, got)
+	}
+}
+
+// execute runs printf with output and returns the events.
+func execute(t *testing.T, output string, stream bool) <-chan command.Event {
+	args := []interface{}{"-c", `printf '%s' "$1"`, "sh", output}
+	if stream {
+		args = append(args, command.WithStreaming())
+	}
+	cmd, err := command.NewCommand(context.Background(), "sh", args...)
+	validateError(t, nil, err)
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	go func() { <-cmd.Wait() }()
+	return events
+}
+
+func TestJSONLines(t *testing.T) {
+	for _, stream := range []bool{true, false} {
+		t.Run(fmt.Sprint("stream=", stream), func(tt *testing.T) {
+			got := []JSONRecord{}
+			for rec := range JSONLines(execute(tt, "{\"ID\":\"a1\"}\n\n{\"ID\":\"b2\"}\nnot json\n", stream)) {
+				if rec.Err != nil {
+					rec.Err = errors.New("error")
+				}
+				got = append(got, rec)
+			}
+			validateResult(tt, []JSONRecord{
+				{Value: map[string]interface{}{"ID": "a1"}},
+				{Value: map[string]interface{}{"ID": "b2"}},
+				{Err: errors.New("error")},
+			}, got)
+		})
+	}
+}
+
+func TestJSONDocument(t *testing.T) {
+	var doc struct {
+		Items []struct {
+			Name string `json:"name"`
+		} `json:"items"`
+	}
+	err := JSONDocument(execute(t, "{\n  \"items\": [\n    {\"name\": \"pod-1\"}\n  ]\n}\n", true), &doc)
+	validateError(t, nil, err)
+	validateResult(t, "pod-1", doc.Items[0].Name)
+}
+
+func TestDF(t *testing.T) {
+	output := "Filesystem     1024-blocks      Used Available Capacity Mounted on\n" +
+		"/dev/sda1        102400000  51200000  51200000      50% /\n" +
+		"tmpfs                 1024         0      1024       0% /mnt/my disk\n" +
+		"broken line\n"
+	got := []DiskUsage{}
+	for u := range DF(execute(t, output, true)) {
+		got = append(got, u)
+	}
+	validateResult(t, []DiskUsage{
+		{Filesystem: "/dev/sda1", Blocks: 102400000, Used: 51200000, Available: 51200000, Capacity: 50, MountedOn: "/"},
+		{Filesystem: "tmpfs", Blocks: 1024, Available: 1024, MountedOn: "/mnt/my disk"},
+		{Err: errors.New(`invalid df line: "broken line"`)},
+	}, got)
+}
+
+func TestPS(t *testing.T) {
+	output := "  PID  PPID COMMAND\n    1     0 /sbin/init splash\n   42     1 sleep 10\n"
+	got := []Process{}
+	for p := range PS(execute(t, output, true)) {
+		got = append(got, p)
+	}
+	validateResult(t, []Process{
+		{Fields: map[string]string{"PID": "1", "PPID": "0", "COMMAND": "/sbin/init splash"}},
+		{Fields: map[string]string{"PID": "42", "PPID": "1", "COMMAND": "sleep 10"}},
+	}, got)
+}
+
+func TestGitPorcelain(t *testing.T) {
+	output := " M command.go\n?? new file.go\nR  old.go -> new.go\nA  a -> b.go\nx\n"
+	got := []GitStatus{}
+	for s := range GitPorcelain(execute(t, output, true)) {
+		got = append(got, s)
+	}
+	validateResult(t, []GitStatus{
+		{Index: ' ', WorkTree: 'M', Path: "command.go"},
+		{Index: '?', WorkTree: '?', Path: "new file.go"},
+		{Index: 'R', WorkTree: ' ', Path: "new.go", OrigPath: "old.go"},
+		{Index: 'A', WorkTree: ' ', Path: "a -> b.go"},
+		{Err: errors.New(`invalid git status line: "x"`)},
+	}, got)
+}
+
+func TestTable(t *testing.T) {
+	testCases := []struct {
+		name   string
+		output string
+		opts   TableOptions
+		rows   []TableRow
+	}{
+		{
+			name: "df",
+			output: "Filesystem     1024-blocks      Used Available Capacity Mounted on\n" +
+				"/dev/sda1        102400000  51200000  51200000      50% /\n" +
+				"tmpfs                 1024         0      1024       0% /mnt/my disk\n",
+			rows: []TableRow{
+				{Fields: map[string]string{"Filesystem": "/dev/sda1", "1024-blocks": "102400000", "Used": "51200000", "Available": "51200000", "Capacity": "50%", "Mounted on": "/"}},
+				{Fields: map[string]string{"Filesystem": "tmpfs", "1024-blocks": "1024", "Used": "0", "Available": "1024", "Capacity": "0%", "Mounted on": "/mnt/my disk"}},
+			},
+		},
+		{
+			name: "kubectl",
+			output: "NAME    READY   STATUS    NODE     NOMINATED NODE   READINESS GATES\n" +
+				"web-1   1/1     Running   node-a   <none>           <none>\n",
+			rows: []TableRow{
+				{Fields: map[string]string{"NAME": "web-1", "READY": "1/1", "STATUS": "Running", "NODE": "node-a", "NOMINATED NODE": "<none>", "READINESS GATES": "<none>"}},
+			},
+		},
+		{
+			name:   "csv",
+			output: "name,size\n\"a, b\",1\nc\nd,2,3\n",
+			opts:   TableOptions{Delimiter: ','},
+			rows: []TableRow{
+				{Fields: map[string]string{"name": "a, b", "size": "1"}},
+				{Fields: map[string]string{"name": "c", "size": ""}},
+				{Err: errors.New(`invalid table line: "d,2,3": 3 fields, want 2`)},
+			},
+		},
+		{
+			name:   "tsvWithoutHeader",
+			output: "a\t1\nb\t2\n",
+			opts:   TableOptions{Delimiter: '\t'},
+			rows: []TableRow{
+				{Fields: map[string]string{"$1": "a", "$2": "1"}},
+				{Fields: map[string]string{"$1": "b", "$2": "2"}},
+			},
+		},
+		{
+			name:   "header",
+			output: "a b c\n",
+			opts:   TableOptions{Header: []string{"x", "rest"}},
+			rows: []TableRow{
+				{Fields: map[string]string{"x": "a", "rest": "b c"}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		for _, stream := range []bool{true, false} {
+			t.Run(fmt.Sprint(tc.name, " stream=", stream), func(tt *testing.T) {
+				got := []TableRow{}
+				for row := range Table(execute(tt, tc.output, stream), tc.opts) {
+					got = append(got, row)
+				}
+				validateResult(tt, tc.rows, got)
+			})
+		}
+	}
+}
+
+func TestTableScan(t *testing.T) {
+	type disk struct {
+		Filesystem string
+		Blocks     int64   `table:"1024-blocks"`
+		Capacity   float64 `table:"Capacity"`
+		MountedOn  string  `table:"Mounted on"`
+		ignored    string
+	}
+	output := "Filesystem     1024-blocks Capacity Mounted on\n/dev/sda1        102400000      50% /\n"
+	got := []disk{}
+	for row := range Table(execute(t, output, true), TableOptions{}) {
+		var d disk
+		validateError(t, nil, row.Scan(&d))
+		got = append(got, d)
+	}
+	validateResult(t, []disk{{Filesystem: "/dev/sda1", Blocks: 102400000, Capacity: 50, MountedOn: "/"}}, got)
+
+	var d disk
+	validateError(t, errors.New(`scan Blocks: strconv.ParseInt: parsing "x": invalid syntax`), TableRow{Fields: map[string]string{"1024-blocks": "x"}}.Scan(&d))
+	validateError(t, errors.New("invalid scan target: parsers.disk"), TableRow{}.Scan(d))
+}
+
+func TestTableMaps(t *testing.T) {
+	rows, err := TableMaps(execute(t, "name,size\na,1\nb,2,3\n", false), TableOptions{Delimiter: ','})
+	validateError(t, errors.New(`invalid table line: "b,2,3": 3 fields, want 2`), err)
+	validateResult(t, []map[string]string{{"name": "a", "size": "1"}}, rows)
+}
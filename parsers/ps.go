@@ -0,0 +1,50 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shebang-go/command"
+)
+
+// Process is a line of `ps -o` output. Fields maps the column headers as
+// printed by ps, e.g. "PID" or "COMMAND", to the values.
+type Process struct {
+	Fields map[string]string
+	Err    error
+}
+
+// PS parses the output of `ps -o <columns>`. The columns are taken from the
+// header line. Only the last column may contain spaces, so columns like
+// args or command should be listed last.
+func PS(events <-chan command.Event) <-chan Process {
+	out := make(chan Process)
+	go func() {
+		defer close(out)
+		var columns []string
+		for line := range lines(events) {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if columns == nil {
+				columns = strings.Fields(line)
+				continue
+			}
+			fields := splitFields(line, len(columns))
+			if len(fields) < len(columns)-1 {
+				out <- Process{Err: fmt.Errorf("invalid ps line: %q", line)}
+				continue
+			}
+			p := Process{Fields: make(map[string]string, len(columns))}
+			for i, column := range columns {
+				if i < len(fields) {
+					p.Fields[column] = fields[i]
+				} else {
+					p.Fields[column] = ""
+				}
+			}
+			out <- p
+		}
+	}()
+	return out
+}
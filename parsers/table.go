@@ -0,0 +1,238 @@
+package parsers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/shebang-go/command"
+)
+
+// TableOptions configures Table.
+type TableOptions struct {
+	// Delimiter separates the fields of delimited output, e.g. ',' for CSV
+	// or '\t' for TSV. Fields may be quoted as in CSV, but must not span
+	// lines. The zero value splits whitespace aligned columns instead.
+	Delimiter rune
+
+	// Header names the columns. If it is nil, the first line is taken as
+	// header unless one of its fields is a number; in that case, the
+	// columns are named "$1", "$2" and so on.
+	Header []string
+}
+
+// TableRow is a row of tabular output. Fields maps the column names to the
+// values.
+type TableRow struct {
+	Fields map[string]string
+	Err    error
+}
+
+// Table parses tabular output like `df`, `ps`, `kubectl get -o wide` or
+// CSV. For whitespace aligned columns, only the last column may contain
+// spaces. Header names containing a single space, like "Mounted on" or
+// "NOMINATED NODE", are detected by comparing the number of header fields
+// with the number of fields of the first row: words separated by a single
+// space are joined from the right until the numbers match. Like the other
+// parsers, the channel has to be read until it is closed.
+func Table(events <-chan command.Event, opts TableOptions) <-chan TableRow {
+	out := make(chan TableRow)
+	go func() {
+		defer close(out)
+		columns := opts.Header
+		var header string
+		for line := range lines(events) {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			fields, err := splitRow(line, opts.Delimiter, 0)
+			if err != nil {
+				out <- TableRow{Err: fmt.Errorf("invalid table line: %q: %w", line, err)}
+				continue
+			}
+			if columns == nil && header == "" {
+				if !isHeader(fields) {
+					columns = positionalColumns(len(fields))
+				} else if opts.Delimiter != 0 {
+					columns = fields
+					continue
+				} else {
+					// the header is split once the first row is known
+					header = line
+					continue
+				}
+			}
+			if columns == nil {
+				columns = joinHeader(header, len(fields))
+			}
+			if opts.Delimiter == 0 {
+				fields, _ = splitRow(line, 0, len(columns))
+			}
+			if len(fields) > len(columns) {
+				out <- TableRow{Err: fmt.Errorf("invalid table line: %q: %d fields, want %d", line, len(fields), len(columns))}
+				continue
+			}
+			row := TableRow{Fields: make(map[string]string, len(columns))}
+			for i, column := range columns {
+				if i < len(fields) {
+					row.Fields[column] = fields[i]
+				} else {
+					row.Fields[column] = ""
+				}
+			}
+			out <- row
+		}
+	}()
+	return out
+}
+
+// TableMaps collects the rows of Table. It returns the error of the first
+// invalid row.
+func TableMaps(events <-chan command.Event, opts TableOptions) ([]map[string]string, error) {
+	rows := []map[string]string{}
+	var err error
+	for row := range Table(events, opts) {
+		if row.Err != nil {
+			if err == nil {
+				err = row.Err
+			}
+			continue
+		}
+		rows = append(rows, row.Fields)
+	}
+	return rows, err
+}
+
+// splitRow splits line at delim, or into at most n whitespace separated
+// fields if delim is 0. n 0 means no limit.
+func splitRow(line string, delim rune, n int) ([]string, error) {
+	if delim == 0 {
+		if n == 0 {
+			return strings.Fields(line), nil
+		}
+		return splitFields(line, n), nil
+	}
+	r := csv.NewReader(strings.NewReader(line))
+	r.Comma = delim
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	return r.Read()
+}
+
+// isHeader reports whether none of fields is a number.
+func isHeader(fields []string) bool {
+	for _, field := range fields {
+		if _, err := strconv.ParseFloat(strings.TrimSuffix(field, "%"), 64); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func positionalColumns(n int) []string {
+	columns := make([]string, n)
+	for i := range columns {
+		columns[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return columns
+}
+
+// joinHeader splits header into n columns. Words separated by a single
+// space are joined from the right while there are more words than n.
+func joinHeader(header string, n int) []string {
+	words := strings.Fields(header)
+	// single[i] is set if words i and i+1 are separated by a single space
+	single := make([]bool, len(words))
+	rest := strings.TrimSpace(header)
+	for i, word := range words[:len(words)-1] {
+		rest = rest[len(word):]
+		single[i] = strings.HasPrefix(rest, " ") && !strings.HasPrefix(rest, "  ") && !strings.HasPrefix(rest, " \t")
+		rest = strings.TrimLeft(rest, " \t")
+	}
+	for i := len(words) - 2; i >= 0 && len(words) > n; i-- {
+		if single[i] {
+			words = append(words[:i], append([]string{words[i] + " " + words[i+1]}, words[i+2:]...)...)
+			single = append(single[:i], single[i+1:]...)
+		}
+	}
+	return words
+}
+
+// Scan stores the fields of the row in the struct pointed to by v. A struct
+// field is set from the column named by its `table` tag, or else from the
+// column matching its name case-insensitively. Fields of kind string, bool,
+// int, uint and float are supported; empty values leave the field
+// unchanged.
+func (r TableRow) Scan(v interface{}) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("invalid scan target: %T", v)
+	}
+	rv = rv.Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value, ok := r.lookup(field)
+		if !ok || value == "" {
+			continue
+		}
+		if err := setField(rv.Field(i), value); err != nil {
+			return fmt.Errorf("scan %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r TableRow) lookup(field reflect.StructField) (string, bool) {
+	if column, ok := field.Tag.Lookup("table"); ok {
+		value, ok := r.Fields[column]
+		return value, ok
+	}
+	for column, value := range r.Fields {
+		if strings.EqualFold(column, field.Name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func setField(f reflect.Value, value string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported kind %s", f.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestCommandPid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		stream bool
+	}{
+		{name: "stream", stream: true},
+		{name: "nostream"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := []interface{}{"-c", "echo $$"}
+			if tc.stream {
+				args = append(args, WithStreaming())
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			validateResult(tt, 0, cmd.Pid())
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			pid := cmd.Pid()
+			validateBool(tt, true, pid > 0)
+			for event := range events {
+				validateResult(tt, pid, event.Pid())
+				validateResult(tt, strconv.Itoa(pid), event.Data().Stdout()[0])
+			}
+			validateResult(tt, pid, (<-cmd.Wait()).Pid())
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithLinePrefix prepends "[label] " to every line of output, including the
+// lines written to sinks (see WithStdoutWriter), to tell the output of
+// concurrently running commands apart. Readiness patterns and classifiers
+// see the line without the prefix. Chunks (see WithChunkStreaming) are not
+// prefixed; of a line split by WithUnboundedLines only the first segment
+// is.
+func WithLinePrefix(label string) Option {
+
+	return func(c *Command) error {
+		c.record("WithLinePrefix", label)
+		if len(label) == 0 {
+			return fmt.Errorf("label cannot be empty")
+		}
+		c.linePrefix = "[" + label + "] "
+		return nil
+	}
+}
+
+// decorate prepends the timestamp (see WithTimestamps) and the line prefix
+// to a line which doesn't continue a partial one.
+func (f *forwarder) decorate(i streamData) streamData {
+	if i.chunk != nil {
+		return i
+	}
+	i.data = f.c.linePrefix + i.data
+	if f.c.timestamps != nil {
+		at := i.at
+		if at.IsZero() {
+			at = time.Now()
+		}
+		i.data = f.c.timestamps(at) + i.data
+	}
+	return i
+}
@@ -0,0 +1,63 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLinePrefix(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []interface{}
+		mock   *CommandServiceMock
+		events []string
+		sink   string
+		err    error
+	}{
+		{
+			name:   "stream",
+			args:   []interface{}{WithStreaming()},
+			mock:   &CommandServiceMock{stdout: "1\n2"},
+			events: []string{"[web] 1", "[web] 2"},
+			sink:   "[web] 1\n[web] 2\n",
+		},
+		{
+			name:   "result",
+			mock:   &CommandServiceMock{stdout: "1", stderr: "2"},
+			events: []string{"[web] 1", "[web] 2"},
+			sink:   "[web] 1\n",
+		},
+		{
+			name:   "unboundedLines",
+			args:   []interface{}{WithStreaming(), WithUnboundedLines(16)},
+			mock:   &CommandServiceMock{stdout: "0123456789abcdefXYZ\n1"},
+			events: []string{"[web] 0123456789abcdef", "XYZ", "[web] 1"},
+			sink:   "[web] 0123456789abcdefXYZ\n[web] 1\n",
+		},
+		{name: "emptyLabel", args: []interface{}{WithLinePrefix("")}, err: errors.New("label cannot be empty")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			var sink bytes.Buffer
+			args := append([]interface{}{withCommandService(tc.mock), WithLinePrefix("web"), WithTee(&sink, nil)}, tc.args...)
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			lines := []string{}
+			for event := range events {
+				lines = append(lines, event.Data().Out()...)
+			}
+			validateResult(tt, tc.events, lines)
+			validateResult(tt, tc.sink, sink.String())
+		})
+	}
+}
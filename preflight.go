@@ -0,0 +1,110 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Requirement describes an executable which must be available.
+type Requirement struct {
+	Name string
+
+	// MinVersion is the minimum semantic version, e.g. "2.30". It is not
+	// checked if empty.
+	MinVersion string
+
+	// VersionArgs are passed to Version to probe the version.
+	VersionArgs []string
+}
+
+// RequirementResult is the outcome of checking a Requirement.
+type RequirementResult struct {
+	Requirement Requirement
+
+	// Path is the resolved path of the executable.
+	Path string
+
+	// Version is the probed version. It is only set if MinVersion is set.
+	Version SemVer
+
+	// Err is a *NotFoundError if the executable is missing, a
+	// *VersionError if it is too old, or the error of the version probe.
+	Err error
+}
+
+// VersionError is reported by Preflight if an executable is older than
+// required.
+type VersionError struct {
+	Name string
+	Have SemVer
+	Want SemVer
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%s: version %s is lower than required %s", e.Name, e.Have, e.Want)
+}
+
+// PreflightReport holds the results of Preflight in the order of the
+// requirements.
+type PreflightReport struct {
+	Results []RequirementResult
+}
+
+// Err returns an error listing all failed requirements or nil if all are
+// met.
+func (r *PreflightReport) Err() error {
+	msgs := []string{}
+	for _, res := range r.Results {
+		if res.Err != nil {
+			msgs = append(msgs, res.Err.Error())
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("preflight failed: %s", strings.Join(msgs, "; "))
+}
+
+// Preflight checks the requirements concurrently. It is intended to run at
+// startup, before a service depending on the executables accepts work.
+func Preflight(ctx context.Context, requirements ...Requirement) *PreflightReport {
+	report := &PreflightReport{Results: make([]RequirementResult, len(requirements))}
+	var wg sync.WaitGroup
+	for i, req := range requirements {
+		wg.Add(1)
+		go func(i int, req Requirement) {
+			defer wg.Done()
+			report.Results[i] = checkRequirement(ctx, req)
+		}(i, req)
+	}
+	wg.Wait()
+	return report
+}
+
+func checkRequirement(ctx context.Context, req Requirement) RequirementResult {
+	res := RequirementResult{Requirement: req}
+	path, err := exec.LookPath(req.Name)
+	if err != nil {
+		res.Err = newNotFoundError(req.Name, err)
+		return res
+	}
+	res.Path = path
+	if req.MinVersion == "" {
+		return res
+	}
+	want, err := ParseSemVer(req.MinVersion)
+	if err != nil {
+		res.Err = fmt.Errorf("%s: invalid minimum version: %w", req.Name, err)
+		return res
+	}
+	if res.Version, res.Err = Version(ctx, path, req.VersionArgs...); res.Err != nil {
+		return res
+	}
+	if res.Version.Compare(want) < 0 {
+		res.Err = &VersionError{Name: req.Name, Have: res.Version, Want: want}
+	}
+	return res
+}
@@ -0,0 +1,55 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreflight(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preflight")
+	validateError(t, nil, err)
+	defer os.RemoveAll(dir)
+	tool := filepath.Join(dir, "tool")
+	validateError(t, nil, ioutil.WriteFile(tool, []byte("#!/bin/sh\necho tool 1.4.2\n"), 0755))
+
+	testCases := []struct {
+		name        string
+		requirement Requirement
+		version     SemVer
+		err         error
+	}{
+		{name: "found", requirement: Requirement{Name: tool}},
+		{name: "version", requirement: Requirement{Name: tool, MinVersion: "1.4"}, version: SemVer{Major: 1, Minor: 4, Patch: 2}},
+		{
+			name:        "tooOld",
+			requirement: Requirement{Name: tool, MinVersion: "v2.0.0"},
+			version:     SemVer{Major: 1, Minor: 4, Patch: 2},
+			err:         &VersionError{Name: tool, Have: SemVer{Major: 1, Minor: 4, Patch: 2}, Want: SemVer{Major: 2}},
+		},
+		{name: "invalidVersion", requirement: Requirement{Name: tool, MinVersion: "latest"}, err: errors.New(tool + `: invalid minimum version: no version found in "latest"`)},
+		{name: "missing", requirement: Requirement{Name: filepath.Join(dir, "missing")}, err: newNotFoundError(filepath.Join(dir, "missing"), nil)},
+	}
+	requirements := []Requirement{}
+	for _, tc := range testCases {
+		requirements = append(requirements, tc.requirement)
+	}
+	report := Preflight(context.Background(), requirements...)
+	validateResult(t, len(testCases), len(report.Results))
+	for i, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			res := report.Results[i]
+			validateResult(tt, tc.requirement, res.Requirement)
+			validateResult(tt, tc.version, res.Version)
+			validateError(tt, tc.err, res.Err)
+		})
+	}
+	validateBool(t, true, report.Err() != nil)
+	validateError(t, nil, Preflight(context.Background(), Requirement{Name: "sh"}).Err())
+}
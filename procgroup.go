@@ -0,0 +1,37 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+)
+
+// WithProcessGroup starts the command in a new process group and delivers
+// kills and stop signals (see WithGracefulStop) to the whole group, so
+// children spawned by scripts do not survive the command. It is only
+// supported on Linux; NewCommand fails on other platforms.
+func WithProcessGroup() Option {
+
+	return func(c *Command) error {
+		c.record("WithProcessGroup")
+		c.processGroup = true
+		c.configurers = append(c.configurers, func(cmd *exec.Cmd) error {
+			return setProcessGroup(sysProcAttr(cmd))
+		})
+		return nil
+	}
+}
+
+// signal sends sig to the started process, or to its process group if
+// WithProcessGroup is set.
+func (c *Command) signal(cmd *exec.Cmd, sig os.Signal) error {
+	if c.processGroup {
+		return signalGroup(cmd.Process.Pid, sig)
+	}
+	return cmd.Process.Signal(sig)
+}
+
+// kill kills the started process, or its process group if WithProcessGroup
+// is set.
+func (c *Command) kill(cmd *exec.Cmd) error {
+	return c.signal(cmd, os.Kill)
+}
@@ -0,0 +1,23 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func setProcessGroup(attr *syscall.SysProcAttr) error {
+	attr.Setpgid = true
+	return nil
+}
+
+func signalGroup(pid int, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return fmt.Errorf("process group: unsupported signal type %T", sig)
+	}
+	if err := syscall.Kill(-pid, s); err != syscall.ESRCH {
+		return err
+	}
+	return os.ErrProcessDone
+}
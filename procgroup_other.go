@@ -0,0 +1,17 @@
+// +build !linux
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func setProcessGroup(attr *syscall.SysProcAttr) error {
+	return fmt.Errorf("process group: %w", ErrUnsupported)
+}
+
+func signalGroup(pid int, sig os.Signal) error {
+	return fmt.Errorf("process group: %w", ErrUnsupported)
+}
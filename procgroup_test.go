@@ -0,0 +1,77 @@
+// +build !integration
+// +build unit
+// +build linux
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCommandProcessGroup(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []interface{}
+		cancel func(cmd *Command, cancel context.CancelFunc)
+	}{
+		{name: "context", cancel: func(cmd *Command, cancel context.CancelFunc) { cancel() }},
+		{name: "reason", cancel: func(cmd *Command, cancel context.CancelFunc) { cmd.CancelWithReason("test") }},
+		{
+			name:   "graceful",
+			args:   []interface{}{WithGracefulStop(syscall.SIGTERM, time.Second)},
+			cancel: func(cmd *Command, cancel context.CancelFunc) { cancel() },
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			args := append([]interface{}{"-c", "sleep 10 & echo $!; wait", WithStreaming(), WithProcessGroup()}, tc.args...)
+			cmd, err := NewCommand(ctx, "sh", args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			child, err := strconv.Atoi((<-events).Data().Stdout()[0])
+			validateError(tt, nil, err)
+
+			started := time.Now()
+			tc.cancel(cmd, cancel)
+			for range events {
+			}
+			<-cmd.Wait()
+			validateBool(tt, true, time.Since(started) < 5*time.Second)
+			for !exited(child) && time.Since(started) < 5*time.Second {
+				time.Sleep(time.Millisecond)
+			}
+			validateBool(tt, true, exited(child))
+		})
+	}
+}
+
+type customSignal struct{}
+
+func (customSignal) String() string { return "custom" }
+func (customSignal) Signal()        {}
+
+func TestSignalGroupUnsupported(t *testing.T) {
+	err := signalGroup(0, customSignal{})
+	validateError(t, errors.New("process group: unsupported signal type command.customSignal"), err)
+}
+
+// exited reports whether pid is gone or a zombie.
+func exited(pid int) bool {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return true
+	}
+	stat := string(data)
+	return strings.HasPrefix(stat[strings.LastIndexByte(stat, ')')+1:], " Z")
+}
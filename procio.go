@@ -0,0 +1,53 @@
+package command
+
+// IOStats holds the I/O counters of a process as reported by the kernel.
+type IOStats struct {
+	// ReadChars and WriteChars count the bytes passed to read and write
+	// system calls, including terminals, pipes and the page cache.
+	ReadChars  int64
+	WriteChars int64
+
+	// ReadBytes and WriteBytes count the bytes actually fetched from or sent
+	// to the storage layer.
+	ReadBytes  int64
+	WriteBytes int64
+}
+
+// ErrNotStarted is returned by methods which require a running process.
+var ErrNotStarted = newCodedError("NOT_STARTED", "command not started")
+
+// WithIOAccounting reads the I/O counters of the process (including reaped
+// children) when it exits and exposes them on the final State. It is only
+// supported on Linux; on other platforms State.IO returns zero values.
+func WithIOAccounting() Option {
+
+	return func(c *Command) error {
+		c.record("WithIOAccounting")
+		c.ioAccounting = true
+		return nil
+	}
+}
+
+// IO returns the current I/O counters of the running process. It can be
+// used to sample the I/O of long running commands.
+func (c *Command) IO() (IOStats, error) {
+	pid := c.Pid()
+	if pid == 0 {
+		return IOStats{}, ErrNotStarted
+	}
+	return readProcIO(pid)
+}
+
+// finalIO waits for the process to exit without reaping it and reads its
+// I/O counters.
+func (c *Command) finalIO() IOStats {
+	pid := c.Pid()
+	if !c.ioAccounting || pid == 0 {
+		return IOStats{}
+	}
+	if err := waitExited(pid); err != nil {
+		return IOStats{}
+	}
+	stats, _ := readProcIO(pid)
+	return stats
+}
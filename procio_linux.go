@@ -0,0 +1,59 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	pPID    = 1         // P_PID idtype of waitid
+	wNoWait = 0x1000000 // WNOWAIT option of waitid
+)
+
+// waitExited blocks until the process exited but leaves it waitable, so its
+// /proc entry is still available.
+func waitExited(pid int) error {
+	var info [128]byte // siginfo_t
+	for {
+		_, _, errno := syscall.Syscall6(syscall.SYS_WAITID, pPID, uintptr(pid), uintptr(unsafe.Pointer(&info[0])), syscall.WEXITED|wNoWait, 0, 0)
+		if errno == syscall.EINTR {
+			continue
+		}
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	}
+}
+
+func readProcIO(pid int) (IOStats, error) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/io", pid))
+	if err != nil {
+		return IOStats{}, err
+	}
+	var stats IOStats
+	fields := map[string]*int64{
+		"rchar":       &stats.ReadChars,
+		"wchar":       &stats.WriteChars,
+		"read_bytes":  &stats.ReadBytes,
+		"write_bytes": &stats.WriteBytes,
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		kv := bytes.SplitN(scanner.Bytes(), []byte(":"), 2)
+		if len(kv) != 2 {
+			continue
+		}
+		if p, ok := fields[string(kv[0])]; ok {
+			if *p, err = strconv.ParseInt(string(bytes.TrimSpace(kv[1])), 10, 64); err != nil {
+				return IOStats{}, err
+			}
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,15 @@
+// +build !linux
+
+package command
+
+import "errors"
+
+var errProcIOUnsupported = errors.New("process I/O accounting is only supported on linux")
+
+func waitExited(pid int) error {
+	return errProcIOUnsupported
+}
+
+func readProcIO(pid int) (IOStats, error) {
+	return IOStats{}, errProcIOUnsupported
+}
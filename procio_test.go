@@ -0,0 +1,50 @@
+// +build !integration
+// +build unit
+// +build linux
+
+package command
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCommandIOAccounting(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   []interface{}
+		expect bool
+	}{
+		{name: "enabled", opts: []interface{}{WithIOAccounting()}, expect: true},
+		{name: "disabled"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append(tc.opts, "-c", "head -c 100000 /dev/zero > /dev/null")...)
+			validateError(tt, nil, err)
+			_, err = cmd.IO()
+			validateError(tt, ErrNotStarted, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			for range events {
+			}
+			state := <-cmd.Wait()
+			validateBool(tt, tc.expect, state.IO().WriteChars >= 100000)
+		})
+	}
+}
+
+func TestReadProcIO(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd, err := NewCommand(ctx, "sleep", "1")
+	validateError(t, nil, err)
+	_, err = cmd.Execute()
+	validateError(t, nil, err)
+	_, err = cmd.IO()
+	validateError(t, nil, err)
+	cancel()
+	<-cmd.Wait()
+
+	_, err = readProcIO(-1)
+	validateBool(t, true, err != nil)
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ProgressEvent is the progress extracted from an output line (see
+// WithProgressParsers).
+type ProgressEvent struct {
+	// Percent is the progress in percent, between 0 and 100.
+	Percent float64
+
+	// Raw is the line the progress was extracted from.
+	Raw string
+}
+
+// ProgressParser extracts the progress in percent from an output line. ok
+// is false if the line holds no progress.
+type ProgressParser func(line string) (percent float64, ok bool)
+
+var (
+	percentPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*%`)
+	ratioPattern   = regexp.MustCompile(`(\d+)\s*/\s*(\d+)`)
+)
+
+// ParsePercent is a ProgressParser for percentages like "42%" or "42.5 %",
+// e.g. of rsync --info=progress2 or curl. The last percentage of a line
+// wins, so lines of progress redraws joined by carriage returns report the
+// latest progress.
+func ParsePercent(line string) (float64, bool) {
+	matches := percentPattern.FindAllStringSubmatch(line, -1)
+	for n := len(matches) - 1; n >= 0; n-- {
+		percent, err := strconv.ParseFloat(matches[n][1], 64)
+		if err == nil && percent <= 100 {
+			return percent, true
+		}
+	}
+	return 0, false
+}
+
+// ParseRatio is a ProgressParser for counts like "3/10" or "3 / 10", e.g.
+// of build tools or package managers. Like ParsePercent, the last count of
+// a line wins.
+func ParseRatio(line string) (float64, bool) {
+	matches := ratioPattern.FindAllStringSubmatch(line, -1)
+	for n := len(matches) - 1; n >= 0; n-- {
+		done, err := strconv.ParseFloat(matches[n][1], 64)
+		if err != nil {
+			continue
+		}
+		total, err := strconv.ParseFloat(matches[n][2], 64)
+		if err == nil && total > 0 && done <= total {
+			return done / total * 100, true
+		}
+	}
+	return 0, false
+}
+
+// WithProgressParsers emits an EventProgress event for every line holding
+// progress, after the event of the line itself, so UIs can render progress
+// bars for wrapped tools. The parsers are tried in order, the first one
+// which extracts a progress wins. Like matchers (see WithMatcher), they
+// see every line as it was read and their events precede the result event
+// in non-streaming mode.
+func WithProgressParsers(parsers ...ProgressParser) Option {
+
+	return func(c *Command) error {
+		c.record("WithProgressParsers", len(parsers))
+		if len(parsers) == 0 {
+			return fmt.Errorf("no progress parsers")
+		}
+		for _, parse := range parsers {
+			if parse == nil {
+				return fmt.Errorf("progress parser cannot be nil")
+			}
+		}
+		c.progressParsers = parsers
+		return nil
+	}
+}
+
+// parseProgress returns the progress of the line i, or nil. The raw line is
+// redacted.
+func (c *Command) parseProgress(i streamData) *ProgressEvent {
+	if i.chunk != nil {
+		return nil
+	}
+	for _, parse := range c.progressParsers {
+		if percent, ok := parse(i.data); ok {
+			return &ProgressEvent{Percent: percent, Raw: c.secrets.redact(i.data)}
+		}
+	}
+	return nil
+}
+
+// Progress returns the progress carried by an EventProgress event, or nil.
+func (evt *commandEvent) Progress() *ProgressEvent {
+	return evt.report
+}
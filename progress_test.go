@@ -0,0 +1,85 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestProgressParsers(t *testing.T) {
+	testCases := []struct {
+		name    string
+		line    string
+		parse   ProgressParser
+		percent float64
+		ok      bool
+	}{
+		{name: "percent", line: "  1,234  42%  1.2MB/s", parse: ParsePercent, percent: 42, ok: true},
+		{name: "percentFraction", line: "progress: 42.5 %", parse: ParsePercent, percent: 42.5, ok: true},
+		{name: "percentLast", line: "10%\r20%\r30%", parse: ParsePercent, percent: 30, ok: true},
+		{name: "percentTooLarge", line: "cpu 150%", parse: ParsePercent},
+		{name: "percentNone", line: "done", parse: ParsePercent},
+		{name: "ratio", line: "[3/4] Linking", parse: ParseRatio, percent: 75, ok: true},
+		{name: "ratioSpaces", line: "step 1 / 4", parse: ParseRatio, percent: 25, ok: true},
+		{name: "ratioZero", line: "0/0", parse: ParseRatio},
+		{name: "ratioExceeded", line: "5/4", parse: ParseRatio},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			percent, ok := tc.parse(tc.line)
+			validateBool(tt, tc.ok, ok)
+			validateResult(tt, tc.percent, percent)
+		})
+	}
+}
+
+func TestProgressEvents(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []interface{}
+		kinds    []EventKind
+		progress []ProgressEvent
+		err      error
+	}{
+		{
+			name:     "stream",
+			args:     []interface{}{WithStreaming(), WithProgressParsers(ParsePercent, ParseRatio)},
+			kinds:    []EventKind{EventStdout, EventStdout, EventProgress, EventStdout, EventProgress, EventStdout},
+			progress: []ProgressEvent{{Percent: 50, Raw: "copying 50%"}, {Percent: 25, Raw: "step 1/4"}},
+		},
+		{
+			name:     "result",
+			args:     []interface{}{WithProgressParsers(ParseRatio)},
+			kinds:    []EventKind{EventProgress, EventResult},
+			progress: []ProgressEvent{{Percent: 25, Raw: "step 1/4"}},
+		},
+		{name: "none", args: []interface{}{WithProgressParsers()}, err: errors.New("no progress parsers")},
+		{name: "nil", args: []interface{}{WithProgressParsers(nil)}, err: errors.New("progress parser cannot be nil")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			mock := &CommandServiceMock{stdout: "start\ncopying 50%\nstep 1/4\ndone\n"}
+			args := append([]interface{}{withCommandService(mock)}, tc.args...)
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			kinds := []EventKind{}
+			progress := []ProgressEvent{}
+			for event := range events {
+				kinds = append(kinds, event.Kind())
+				if event.Kind() == EventProgress {
+					progress = append(progress, *event.Progress())
+				}
+			}
+			validateResult(tt, tc.kinds, kinds)
+			validateResult(tt, tc.progress, progress)
+		})
+	}
+}
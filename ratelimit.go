@@ -0,0 +1,75 @@
+package command
+
+import (
+	"fmt"
+	"time"
+)
+
+// RateLimitError is set on the summary event which is emitted when lines have
+// been suppressed by the rate limiter (see WithLineRateLimit).
+type RateLimitError struct {
+	// Suppressed is the number of lines dropped since the last emitted event.
+	Suppressed int
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded: %d lines suppressed", e.Suppressed)
+}
+
+// lineLimiter is a simple token bucket which counts the lines it rejects.
+type lineLimiter struct {
+	rate       float64
+	burst      float64
+	tokens     float64
+	last       time.Time
+	suppressed int
+}
+
+func newLineLimiter(linesPerSec float64, burst int) *lineLimiter {
+	return &lineLimiter{rate: linesPerSec, burst: float64(burst), tokens: float64(burst)}
+}
+
+// allow reports whether a line may be emitted at time now. Rejected lines are
+// counted until they are collected by flush.
+func (l *lineLimiter) allow(now time.Time) bool {
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	if l.tokens < 1 {
+		l.suppressed++
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// flush returns a *RateLimitError for the suppressed lines, or nil if no line
+// has been suppressed since the last call.
+func (l *lineLimiter) flush() error {
+	if l.suppressed == 0 {
+		return nil
+	}
+	err := &RateLimitError{Suppressed: l.suppressed}
+	l.suppressed = 0
+	return err
+}
+
+// WithLineRateLimit throttles the emission of streamed events to linesPerSec
+// with bursts of up to burst lines. Excess lines are dropped and reported by
+// a summary event carrying a *RateLimitError before the next emitted line and
+// at the end of the stream. The option only affects streaming mode.
+func WithLineRateLimit(linesPerSec float64, burst int) Option {
+
+	return func(c *Command) error {
+		c.record("WithLineRateLimit", fmt.Sprintf("%v lines/s, burst %d", linesPerSec, burst))
+		if linesPerSec <= 0 || burst < 1 {
+			return fmt.Errorf("invalid rate limit: %v lines/s, burst %d", linesPerSec, burst)
+		}
+		c.limiter = newLineLimiter(linesPerSec, burst)
+		return nil
+	}
+}
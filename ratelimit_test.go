@@ -0,0 +1,106 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLineLimiter(t *testing.T) {
+	testCases := []struct {
+		name       string
+		rate       float64
+		burst      int
+		lines      int
+		interval   time.Duration
+		expect     int
+		suppressed int
+	}{
+		{name: "burst", rate: 1, burst: 2, lines: 5, expect: 2, suppressed: 3},
+		{name: "refill", rate: 10, burst: 1, lines: 5, interval: 100 * time.Millisecond, expect: 5},
+		{name: "partialRefill", rate: 10, burst: 1, lines: 4, interval: 50 * time.Millisecond, expect: 2, suppressed: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			limiter := newLineLimiter(tc.rate, tc.burst)
+			now := time.Now()
+			got := 0
+			for i := 0; i < tc.lines; i++ {
+				if limiter.allow(now.Add(time.Duration(i) * tc.interval)) {
+					got++
+				}
+			}
+			validateResult(tt, tc.expect, got)
+			validateResult(tt, tc.suppressed, limiter.suppressed)
+		})
+	}
+}
+
+func TestCommandExecuteRateLimit(t *testing.T) {
+	testCases := []struct {
+		name       string
+		args       []interface{}
+		expect     []string
+		suppressed int
+		readErrors int
+		err        error
+	}{
+		{
+			name:       "suppressed",
+			args:       []interface{}{WithStreaming(), WithLineRateLimit(0.001, 2), withCommandService(&CommandServiceMock{stdout: "1\n2\n3\n4\n5"})},
+			expect:     []string{"1", "2"},
+			suppressed: 3,
+		},
+		{
+			name:       "errorsNotThrottled",
+			args:       []interface{}{WithStreaming(), WithLineRateLimit(0.001, 1), WithMaxLineSize(4), withCommandService(&CommandServiceMock{stdout: "1\n2\n123456789"})},
+			expect:     []string{"1"},
+			suppressed: 1,
+			readErrors: 1,
+		},
+		{
+			name:   "unlimited",
+			args:   []interface{}{WithStreaming(), WithLineRateLimit(1, 10), withCommandService(&CommandServiceMock{stdout: "1\n2\n3"})},
+			expect: []string{"1", "2", "3"},
+		},
+		{
+			name: "invalid",
+			args: []interface{}{WithLineRateLimit(0, 1)},
+			err:  errors.New("invalid rate limit: 0 lines/s, burst 1"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", tc.args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			got := []string{}
+			suppressed := 0
+			readErrors := 0
+			for event := range events {
+				var rateErr *RateLimitError
+				if errors.As(event.Error(), &rateErr) {
+					suppressed += rateErr.Suppressed
+					continue
+				}
+				if errors.Is(event.Error(), ErrLineTooLong) {
+					readErrors++
+					continue
+				}
+				got = append(got, event.Data().Stdout()...)
+			}
+			<-cmd.Wait()
+			validateResult(tt, tc.expect, got)
+			validateResult(tt, tc.suppressed, suppressed)
+			validateResult(tt, tc.readErrors, readErrors)
+		})
+	}
+}
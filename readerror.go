@@ -0,0 +1,86 @@
+package command
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+)
+
+// Stream names used by ReadError.
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+var (
+	// ErrRead is matched by every ReadError.
+	ErrRead = newCodedError("READ_FAILED", "read failed")
+
+	// ErrLineTooLong is matched by a ReadError if an output line exceeded
+	// the maximum line length of the scanner.
+	ErrLineTooLong = newCodedError("LINE_TOO_LONG", "line too long")
+)
+
+// ReadError is the error of an event if reading an output stream failed.
+// It is not a failure of the process: the process error is reported by
+// State.Error, read errors are reported by State.ReadErrors.
+type ReadError struct {
+	// Stream is StreamStdout or StreamStderr.
+	Stream string
+
+	// Kind is ErrLineTooLong, ErrInvalidUTF8 or ErrRead.
+	Kind error
+	Err  error
+}
+
+func newReadError(isStderr bool, err error) *ReadError {
+	e := &ReadError{Stream: StreamStdout, Kind: ErrRead, Err: err}
+	if isStderr {
+		e.Stream = StreamStderr
+	}
+	if errors.Is(err, bufio.ErrTooLong) {
+		e.Kind = ErrLineTooLong
+	}
+	return e
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("read %s: %v", e.Stream, e.Err)
+}
+
+func (e *ReadError) Unwrap() error { return e.Err }
+
+func (e *ReadError) Is(target error) bool {
+	return target == ErrRead || target == e.Kind
+}
+
+// ErrorCode returns the code of Kind.
+func (e *ReadError) ErrorCode() string { return ErrorCode(e.Kind) }
+
+// addReadError records err for State.ReadErrors.
+func (c *Command) addReadError(err error) {
+	c.mu.Lock()
+	c.readErrors = append(c.readErrors, err)
+	c.mu.Unlock()
+}
+
+func (c *Command) readErrorList() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.readErrors
+}
+
+// WithMaxLineSize sets the maximum length of an output line to n bytes,
+// bufio.MaxScanTokenSize (64KiB) by default. A longer line ends the output
+// of its stream with a *ReadError event matching ErrLineTooLong.
+func WithMaxLineSize(n int) Option {
+
+	return func(c *Command) error {
+		c.record("WithMaxLineSize", n)
+		if n < 1 {
+			return fmt.Errorf("invalid max line size: %d", n)
+		}
+		c.maxLineSize = n
+		return nil
+	}
+}
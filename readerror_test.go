@@ -0,0 +1,108 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadErrors(t *testing.T) {
+	long := strings.Repeat("x", 70000) + "\n"
+	testCases := []struct {
+		name   string
+		mock   *CommandServiceMock
+		stream bool
+		stdout []string
+		errs   []string
+	}{
+		{name: "none", mock: &CommandServiceMock{stdout: "1\n"}, stdout: []string{"1"}},
+		{name: "stdout", mock: &CommandServiceMock{stdout: long, stderr: "1\n"}, stdout: []string{}, errs: []string{StreamStdout}},
+		{name: "stderr", mock: &CommandServiceMock{stdout: "1\n", stderr: long}, stdout: []string{"1"}, errs: []string{StreamStderr}},
+		{name: "stream", mock: &CommandServiceMock{stdout: "1\n", stderr: long}, stream: true, errs: []string{StreamStderr}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := []interface{}{withCommandService(tc.mock)}
+			if tc.stream {
+				args = append(args, WithStreaming())
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			errs := []string{}
+			for event := range events {
+				var readErr *ReadError
+				if errors.As(event.Error(), &readErr) {
+					validateBool(tt, true, errors.Is(readErr, ErrRead))
+					validateBool(tt, true, errors.Is(readErr, ErrLineTooLong))
+					validateResult(tt, "LINE_TOO_LONG", ErrorCode(event.Error()))
+					errs = append(errs, readErr.Stream)
+					continue
+				}
+				if !tc.stream {
+					validateResult(tt, tc.stdout, event.Data().Stdout())
+				}
+			}
+			if tc.stream {
+				validateResult(tt, tc.errs, errs)
+			}
+			state := <-cmd.Wait()
+			validateError(tt, nil, state.Error())
+			got := []string{}
+			for _, err := range state.ReadErrors() {
+				got = append(got, err.(*ReadError).Stream)
+			}
+			want := tc.errs
+			if want == nil {
+				want = []string{}
+			}
+			validateResult(tt, want, got)
+		})
+	}
+}
+
+func TestMaxLineSize(t *testing.T) {
+	testCases := []struct {
+		name   string
+		size   int
+		length int
+		lines  int
+		err    error
+		optErr error
+	}{
+		{name: "long", size: 200000, length: 100000, lines: 1},
+		{name: "exact", size: 10, length: 10, lines: 1},
+		{name: "tooLong", size: 10, length: 11, err: ErrLineTooLong},
+		{name: "invalid", size: 0, optErr: errors.New("invalid max line size: 0")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			mock := &CommandServiceMock{stdout: strings.Repeat("x", tc.length) + "\n"}
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(mock), WithStreaming(), WithMaxLineSize(tc.size))
+			validateError(tt, tc.optErr, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			lines := 0
+			var readErr error
+			for event := range events {
+				if event.Error() != nil {
+					readErr = event.Error()
+					continue
+				}
+				validateResult(tt, tc.length, len(event.Data().Stdout()[0]))
+				lines++
+			}
+			<-cmd.Wait()
+			validateResult(tt, tc.lines, lines)
+			validateBool(tt, true, errors.Is(readErr, tc.err))
+		})
+	}
+}
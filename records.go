@@ -0,0 +1,145 @@
+package command
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithRecords folds continuation lines into the event of the preceding line
+// in streaming mode, so that multi-line records like stack traces or
+// wrapped log messages are delivered as one event with one line per line
+// of the record. A record starts with a line for which start returns true
+// and is emitted before the next record of the same stream starts, once no
+// line was read for flushAfter unless flushAfter is zero, and at the end of
+// the output. A record carries the time of its first line. Events carrying
+// an error flush all records first, partial lines, progress lines and
+// chunks flush the record of their stream and are not folded; match and
+// progress events (see WithMatcher) pass through right away. WithBatching
+// is ignored if records are enabled.
+func WithRecords(start func(line string) bool, flushAfter time.Duration) Option {
+
+	return func(c *Command) error {
+		c.record("WithRecords", funcValue, flushAfter)
+		if start == nil {
+			return fmt.Errorf("record start cannot be nil")
+		}
+		if flushAfter < 0 {
+			return fmt.Errorf("invalid flush interval: %v", flushAfter)
+		}
+		c.recordStart = start
+		c.recordFlush = flushAfter
+		return nil
+	}
+}
+
+// pendingRecord is a record which has not been emitted yet.
+type pendingRecord struct {
+	lines []string
+	first time.Time
+}
+
+// recordEvents returns the events of in with continuation lines folded into
+// records.
+func (c *Command) recordEvents(in <-chan Event) <-chan Event {
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+		// records of stdout and stderr
+		var records [2]pendingRecord
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		send := func(event Event) bool {
+			select {
+			case <-c.ctx.Done():
+				return false
+			case out <- event:
+				return true
+			}
+		}
+		flush := func(stream int) bool {
+			r := &records[stream]
+			if len(r.lines) == 0 {
+				return true
+			}
+			data := newCommandResult(r.lines, []string{})
+			if stream == 1 {
+				data = newCommandResult([]string{}, r.lines)
+			}
+			event := c.newEvent(data, nil)
+			event.time = r.first
+			*r = pendingRecord{}
+			return send(event)
+		}
+		// stopTimer stops the flush timer; timeout is set while it runs
+		stopTimer := func() {
+			if timeout != nil && !timer.Stop() {
+				<-timer.C
+			}
+			timeout = nil
+		}
+		flushAll := func() bool {
+			stopTimer()
+			return flush(0) && flush(1)
+		}
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					flushAll()
+					return
+				}
+				if kind := v.Kind(); kind == EventMatch || kind == EventProgress {
+					if !send(v) {
+						return
+					}
+					continue
+				}
+				if v.Error() != nil {
+					if !flushAll() || !send(v) {
+						return
+					}
+					continue
+				}
+				d := v.Data()
+				stream := 0
+				if len(d.Stderr()) > 0 {
+					stream = 1
+				}
+				if d.Partial() || d.Progress() || d.Bytes() != nil {
+					if !flush(stream) || !send(v) {
+						return
+					}
+					continue
+				}
+				r := &records[stream]
+				for _, line := range d.Out() {
+					if len(r.lines) > 0 && c.recordStart(line) {
+						if !flush(stream) {
+							return
+						}
+					}
+					if len(r.lines) == 0 {
+						r.first = v.Time()
+					}
+					r.lines = append(r.lines, line)
+				}
+				if c.recordFlush > 0 {
+					stopTimer()
+					if timer == nil {
+						timer = time.NewTimer(c.recordFlush)
+					} else {
+						timer.Reset(c.recordFlush)
+					}
+					timeout = timer.C
+				}
+			case <-timeout:
+				timeout = nil
+				if !flushAll() {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
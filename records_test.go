@@ -0,0 +1,143 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// notIndented starts a record for lines of stack traces which are not
+// indented.
+func notIndented(line string) bool {
+	return !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "goroutine")
+}
+
+func TestRecords(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      []string
+		flushAfter time.Duration
+		records    [][]string
+		err        error
+	}{
+		{
+			name:  "stackTrace",
+			input: []string{"start", "panic: boom", "goroutine 1", "\tmain.go:3", "done"},
+			records: [][]string{
+				{"start"},
+				{"panic: boom", "goroutine 1", "\tmain.go:3"},
+				{"done"},
+			},
+		},
+		{
+			name:  "streams",
+			input: []string{"a", "\t1", "e:e", "e:\t2", "\t3", "b"},
+			// the stderr record is pending until the end of the output
+			records: [][]string{
+				{"a", "\t1", "\t3"},
+				{"b"},
+				{"e", "\t2"},
+			},
+		},
+		{
+			name:       "flushAfter",
+			input:      []string{"a", "\t1", ".", "\t2"},
+			flushAfter: time.Millisecond,
+			records: [][]string{
+				{"a", "\t1"},
+				{"\t2"},
+			},
+		},
+		{
+			name:  "error",
+			input: []string{"a", "e:b", "\t1", "!", "c"},
+			records: [][]string{
+				{"a", "\t1"},
+				{"b"},
+				{"error"},
+				{"c"},
+			},
+		},
+		{name: "invalidFlush", flushAfter: -1, err: errors.New("invalid flush interval: -1ns")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", WithStreaming(), WithRecords(notIndented, tc.flushAfter))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			// lines prefixed with e: are stderr lines, ! is an error and .
+			// waits for a record to be flushed by the timer
+			in := make(chan Event)
+			events := cmd.recordEvents(in)
+			records := [][]string{}
+			receive := func(event Event) {
+				if event.Error() != nil {
+					records = append(records, []string{"error"})
+					return
+				}
+				records = append(records, event.Data().Out())
+			}
+			for _, line := range tc.input {
+				if line == "." {
+					receive(<-events)
+					continue
+				}
+				event := cmd.newEvent(newStreamData(strings.TrimPrefix(line, "e:"), strings.HasPrefix(line, "e:")), nil)
+				if line == "!" {
+					event = cmd.newEvent(newStreamData("", false), errors.New("errRead"))
+				}
+			SendLoop:
+				for {
+					select {
+					case in <- event:
+						break SendLoop
+					case record := <-events:
+						receive(record)
+					}
+				}
+			}
+			close(in)
+			for event := range events {
+				receive(event)
+			}
+			validateResult(tt, tc.records, records)
+		})
+	}
+}
+
+func TestRecordsExecute(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []interface{}
+		records [][]string
+	}{
+		{name: "records", records: [][]string{{"a", "\t1"}, {"b"}}},
+		{name: "batchingIgnored", args: []interface{}{WithBatching(10, 0)}, records: [][]string{{"a", "\t1"}, {"b"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := append([]interface{}{withCommandService(&CommandServiceMock{stdout: "a\n\t1\nb"}), WithStreaming(), WithRecords(notIndented, 0)}, tc.args...)
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			records := [][]string{}
+			for event := range events {
+				records = append(records, event.Data().Out())
+			}
+			validateResult(tt, tc.records, records)
+		})
+	}
+}
+
+func TestRecordsNilStart(t *testing.T) {
+	_, err := NewCommand(context.Background(), "true", WithRecords(nil, 0))
+	validateError(t, errors.New("record start cannot be nil"), err)
+}
@@ -0,0 +1,51 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// WithRedaction replaces every match of patterns and every occurrence of
+// secrets with "***" in the output of the command: in events, results, sinks
+// (see WithStdoutWriter) and in String. Like the secrets of WithSecrets, it
+// is applied to lines after they were filtered, transformed and decorated;
+// chunks (see WithChunkStreaming) are not redacted. Submatches of matchers
+// (see WithMatcher) overlapping a redacted part of the line are replaced
+// entirely. The option can be used multiple times.
+func WithRedaction(patterns []*regexp.Regexp, secrets ...string) Option {
+
+	return func(c *Command) error {
+		exprs := make([]string, len(patterns))
+		for i, re := range patterns {
+			exprs[i] = fmt.Sprint(re)
+		}
+		c.record("WithRedaction", exprs, strings.TrimSuffix(strings.Repeat(redacted+" ", len(secrets)), " "))
+		for _, re := range patterns {
+			if re == nil {
+				return fmt.Errorf("redaction pattern cannot be nil")
+			}
+		}
+		for _, re := range patterns {
+			c.secrets.addPattern(re)
+		}
+		for _, secret := range secrets {
+			c.secrets.add(secret)
+		}
+		return nil
+	}
+}
+
+// String returns the command line with secrets redacted (see WithSecrets
+// and WithRedaction). Arguments containing whitespace or quotes are quoted.
+func (c *Command) String() string {
+	words := make([]string, 0, len(c.args)+1)
+	for _, arg := range append([]string{c.name}, c.args...) {
+		arg = c.secrets.redact(arg)
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
+}
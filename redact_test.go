@@ -0,0 +1,107 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	token := regexp.MustCompile(`token=\S+`)
+	testCases := []struct {
+		name  string
+		args  []interface{}
+		lines []string
+		err   error
+	}{
+		{
+			name:  "stream",
+			args:  []interface{}{WithStreaming(), WithRedaction([]*regexp.Regexp{token}, "hunter2")},
+			lines: []string{"login ***", "password ***"},
+		},
+		{
+			name:  "result",
+			args:  []interface{}{WithRedaction([]*regexp.Regexp{token}), WithRedaction(nil, "hunter2")},
+			lines: []string{"login ***", "password ***"},
+		},
+		{
+			name:  "decorated",
+			args:  []interface{}{WithLinePrefix("token=x"), WithRedaction([]*regexp.Regexp{token})},
+			lines: []string{"[*** login ***", "[*** password hunter2"},
+		},
+		{name: "nilPattern", args: []interface{}{WithRedaction([]*regexp.Regexp{nil})}, err: errors.New("redaction pattern cannot be nil")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			var sink bytes.Buffer
+			mock := &CommandServiceMock{stdout: "login token=abc\npassword hunter2\n"}
+			args := append([]interface{}{withCommandService(mock), WithTee(&sink, nil)}, tc.args...)
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			lines := []string{}
+			for event := range events {
+				lines = append(lines, event.Data().Out()...)
+			}
+			validateResult(tt, tc.lines, lines)
+			validateResult(tt, tc.lines[0]+"\n"+tc.lines[1]+"\n", sink.String())
+		})
+	}
+}
+
+func TestRedactionMatch(t *testing.T) {
+	token := regexp.MustCompile(`token=\S+`)
+	testCases := []struct {
+		name  string
+		args  []interface{}
+		match Match
+	}{
+		{
+			name:  "pattern",
+			args:  []interface{}{WithRedaction([]*regexp.Regexp{token}), WithMatcher("tok", regexp.MustCompile(`(\w+) token=(?P<v>\S+)`))},
+			match: Match{Name: "tok", Line: "login ***", Stream: StreamStdout, Groups: []string{"***", "login", "***"}, Named: map[string]string{"v": "***"}},
+		},
+		{
+			name:  "secret",
+			args:  []interface{}{WithRedaction(nil, "abc"), WithMatcher("tok", regexp.MustCompile(`token=(?P<v>\w)(\w+)`))},
+			match: Match{Name: "tok", Line: "login token=***", Stream: StreamStdout, Groups: []string{"***", "***", "***"}, Named: map[string]string{"v": "***"}},
+		},
+		{
+			name:  "unrelated",
+			args:  []interface{}{WithRedaction(nil, "abc"), WithMatcher("tok", regexp.MustCompile(`^(?P<v>\w+) (x)?`))},
+			match: Match{Name: "tok", Line: "login token=***", Stream: StreamStdout, Groups: []string{"login ", "login", ""}, Named: map[string]string{"v": "login"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := append([]interface{}{WithStreaming(), withCommandService(&CommandServiceMock{stdout: "login token=abc"})}, tc.args...)
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			matches := []Match{}
+			for event := range events {
+				if event.Kind() == EventMatch {
+					matches = append(matches, *event.Match())
+				}
+			}
+			validateResult(tt, []Match{tc.match}, matches)
+		})
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	cmd, err := NewCommand(context.Background(), "curl", "-H", "Authorization: Bearer abc", "--data", "token=xyz", "", WithRedaction([]*regexp.Regexp{regexp.MustCompile(`token=\S+`)}, "abc"))
+	validateError(t, nil, err)
+	validateResult(t, `curl -H "Authorization: Bearer ***" --data *** ""`, cmd.String())
+	validateResult(t, []OptionInfo{{Name: "WithRedaction", Value: "[token=\\S+] ***"}}, cmd.Options())
+}
@@ -0,0 +1,107 @@
+package command
+
+import (
+	"fmt"
+	"os"
+)
+
+// WithOutputFile appends the stdout and stderr output to the file at path
+// while events and results work unchanged (see WithTee). Once the file
+// would exceed rotateBytes, it is renamed to path.1, an existing path.1 to
+// path.2 and so on, and a new file is started; segments beyond keep are
+// removed. A rotateBytes of 0 disables the rotation. Lines are never split
+// across segments, unless a single line exceeds rotateBytes.
+func WithOutputFile(path string, rotateBytes int64, keep int) Option {
+
+	return func(c *Command) error {
+		c.record("WithOutputFile", path, rotateBytes, keep)
+		if len(path) == 0 {
+			return fmt.Errorf("path cannot be empty")
+		}
+		if rotateBytes < 0 {
+			return fmt.Errorf("invalid rotate bytes: %d", rotateBytes)
+		}
+		if keep < 0 {
+			return fmt.Errorf("invalid keep: %d", keep)
+		}
+		f := &rotatingFile{path: path, rotateBytes: rotateBytes, keep: keep}
+		s := c.writerSinks()
+		s.stdout, s.stderr, s.tee = f, f, true
+		return nil
+	}
+}
+
+// rotatingFile is a file writer which rotates the file by size. The file is
+// opened on the first write.
+type rotatingFile struct {
+	path        string
+	rotateBytes int64
+	keep        int
+	file        *os.File
+	size        int64
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.rotateBytes > 0 && f.size > 0 && f.size+int64(len(p)) > f.rotateBytes {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts the segments and opens a new file.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	if err := os.Remove(f.segment(f.keep)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for n := f.keep - 1; n >= 0; n-- {
+		if err := os.Rename(f.segment(n), f.segment(n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return f.open()
+}
+
+// segment returns the path of the n-th rotated segment, the current file
+// for 0.
+func (f *rotatingFile) segment(n int) string {
+	if n == 0 {
+		return f.path
+	}
+	return fmt.Sprintf("%s.%d", f.path, n)
+}
+
+func (f *rotatingFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
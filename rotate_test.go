@@ -0,0 +1,80 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputFile(t *testing.T) {
+	testCases := []struct {
+		name        string
+		existing    string
+		rotateBytes int64
+		keep        int
+		stderr      bool
+		files       []string
+		err         error
+	}{
+		{name: "noRotation", files: []string{"1\n2\n3\n4\n5\n6\n7\n8\n9\n"}},
+		{name: "append", existing: "0\n", files: []string{"0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n"}},
+		{name: "rotate", rotateBytes: 4, keep: 2, files: []string{"9\n", "7\n8\n", "5\n6\n"}},
+		{name: "stderr", rotateBytes: 4, keep: 1, stderr: true, files: []string{"9\n", "7\n8\n"}},
+		{name: "keepNone", rotateBytes: 4, files: []string{"9\n"}},
+		{name: "rotateExisting", existing: "0\n", rotateBytes: 4, keep: 5, files: []string{"8\n9\n", "6\n7\n", "4\n5\n", "2\n3\n", "0\n1\n"}},
+		{name: "invalidRotateBytes", rotateBytes: -1, err: errors.New("invalid rotate bytes: -1")},
+		{name: "invalidKeep", keep: -1, err: errors.New("invalid keep: -1")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			dir, err := ioutil.TempDir("", "rotate")
+			validateError(tt, nil, err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "out.log")
+			if tc.existing != "" {
+				validateError(tt, nil, ioutil.WriteFile(path, []byte(tc.existing), 0644))
+			}
+			mock := &CommandServiceMock{stdout: "1\n2\n3\n4\n5\n6\n7\n8\n9"}
+			if tc.stderr {
+				mock = &CommandServiceMock{stderr: mock.stdout}
+			}
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(mock), WithStreaming(), WithOutputFile(path, tc.rotateBytes, tc.keep))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			lines := 0
+			for range events {
+				lines++
+			}
+			validateResult(tt, 9, lines)
+			validateError(tt, nil, (<-cmd.Wait()).Error())
+			files := []string{}
+			for n := 0; ; n++ {
+				name := path
+				if n > 0 {
+					name = (&rotatingFile{path: path}).segment(n)
+				}
+				data, err := ioutil.ReadFile(name)
+				if err != nil {
+					break
+				}
+				files = append(files, string(data))
+			}
+			validateResult(tt, tc.files, files)
+		})
+	}
+}
+
+func TestOutputFileEmptyPath(t *testing.T) {
+	_, err := NewCommand(context.Background(), "true", WithOutputFile("", 0, 0))
+	validateError(t, errors.New("path cannot be empty"), err)
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"context"
+	"strings"
+)
+
+// Spec describes a command which can be executed repeatedly. Every execution
+// creates a new Command.
+type Spec struct {
+	Name string
+
+	// Args are passed to NewCommand, i.e. they may contain both arguments
+	// and Options.
+	Args []interface{}
+
+	// Key identifies the spec for duplicate suppression (see Group). If
+	// empty, it is derived from Name and the string arguments. Options
+	// cannot be compared, so specs with Options are only deduplicated if
+	// Key is set.
+	Key string
+}
+
+// key returns the spec's Key or derives it from name and arguments. ok is
+// false if the spec has Options but no Key.
+func (s Spec) key() (key string, ok bool) {
+	if s.Key != "" {
+		return s.Key, true
+	}
+	parts := []string{s.Name}
+	for _, arg := range s.Args {
+		switch v := arg.(type) {
+		case Option:
+			return "", false
+		case string:
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, "\x00"), true
+}
+
+// Command returns a new Command for the spec.
+func (s Spec) Command(ctx context.Context) (*Command, error) {
+	return NewCommand(ctx, s.Name, s.Args...)
+}
+
+// Result holds the collected output and the final state of an execution.
+type Result struct {
+	Data  Data
+	State State
+
+	// Err is set if the command could not be executed. Data and State are
+	// nil in this case.
+	Err error
+}
+
+// StdoutPage returns up to limit stdout lines starting at line offset. It
+// returns an empty slice if offset is beyond the end or Data is nil. The
+// returned slice shares its storage with Data.
+func (r *Result) StdoutPage(offset, limit int) []string {
+	if r.Data == nil {
+		return []string{}
+	}
+	return page(r.Data.Stdout(), offset, limit)
+}
+
+// StderrPage is like StdoutPage for stderr lines.
+func (r *Result) StderrPage(offset, limit int) []string {
+	if r.Data == nil {
+		return []string{}
+	}
+	return page(r.Data.Stderr(), offset, limit)
+}
+
+func page(lines []string, offset, limit int) []string {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(lines) || limit <= 0 {
+		return []string{}
+	}
+	end := offset + limit
+	if end > len(lines) || end < offset {
+		end = len(lines)
+	}
+	return lines[offset:end]
+}
+
+// run executes spec to completion and collects its output.
+func run(ctx context.Context, spec Spec) (*Result, error) {
+	cmd, err := spec.Command(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cmd.Execute(); err != nil {
+		return nil, err
+	}
+	result, _, _ := cmd.Drain(context.Background())
+	return result, nil
+}
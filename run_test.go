@@ -0,0 +1,34 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"testing"
+)
+
+func TestResultPage(t *testing.T) {
+	result := &Result{Data: newCommandResult([]string{"1", "2", "3", "4", "5"}, []string{"e1", "e2"})}
+	testCases := []struct {
+		name   string
+		result *Result
+		offset int
+		limit  int
+		stdout []string
+		stderr []string
+	}{
+		{name: "first", result: result, offset: 0, limit: 2, stdout: []string{"1", "2"}, stderr: []string{"e1", "e2"}},
+		{name: "middle", result: result, offset: 2, limit: 2, stdout: []string{"3", "4"}, stderr: []string{}},
+		{name: "last", result: result, offset: 4, limit: 10, stdout: []string{"5"}, stderr: []string{}},
+		{name: "beyond", result: result, offset: 5, limit: 2, stdout: []string{}, stderr: []string{}},
+		{name: "negativeOffset", result: result, offset: -1, limit: 1, stdout: []string{"1"}, stderr: []string{"e1"}},
+		{name: "zeroLimit", result: result, offset: 0, limit: 0, stdout: []string{}, stderr: []string{}},
+		{name: "noData", result: &Result{}, offset: 0, limit: 1, stdout: []string{}, stderr: []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			validateResult(tt, tc.stdout, tc.result.StdoutPage(tc.offset, tc.limit))
+			validateResult(tt, tc.stderr, tc.result.StderrPage(tc.offset, tc.limit))
+		})
+	}
+}
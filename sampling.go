@@ -0,0 +1,60 @@
+package command
+
+import "fmt"
+
+// WithSampleLines forwards only every nth stdout line, plus the first and the
+// last line. Stderr lines are always forwarded. The number of skipped lines
+// is reported by Stats.SkippedLines. This is useful for tools which spam
+// progress output where full capture is useless.
+func WithSampleLines(n int) Option {
+
+	return func(c *Command) error {
+		c.record("WithSampleLines", n)
+		if n < 1 {
+			return fmt.Errorf("invalid sample rate: %d", n)
+		}
+		c.sampleEvery = n
+		return nil
+	}
+}
+
+// lineSampler selects the lines of a single stream.
+type lineSampler struct {
+	every   int
+	count   int
+	pending *streamData
+	skipped int64
+}
+
+func (c *Command) newSampler() *lineSampler {
+	if c.sampleEvery <= 1 {
+		return nil
+	}
+	return &lineSampler{every: c.sampleEvery}
+}
+
+// keep reports whether line is forwarded. A skipped line is held back
+// because it might be the last one.
+func (s *lineSampler) keep(line streamData) bool {
+	n := s.count
+	s.count++
+	if s.pending != nil {
+		s.skipped++
+		s.pending = nil
+	}
+	if n%s.every == 0 {
+		return true
+	}
+	s.pending = &line
+	return false
+}
+
+// last returns the held back last line, if any.
+func (s *lineSampler) last() (streamData, bool) {
+	if s.pending == nil {
+		return streamData{}, false
+	}
+	line := *s.pending
+	s.pending = nil
+	return line, true
+}
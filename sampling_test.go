@@ -0,0 +1,62 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCommandSampleLines(t *testing.T) {
+	lines := func(n int) string {
+		l := make([]string, n)
+		for i := range l {
+			l[i] = string(rune('a' + i))
+		}
+		return strings.Join(l, "\n")
+	}
+	testCases := []struct {
+		name    string
+		every   int
+		stream  bool
+		mock    *CommandServiceMock
+		stdout  []string
+		stderr  []string
+		skipped int64
+		err     error
+	}{
+		{name: "aligned", every: 3, mock: &CommandServiceMock{stdout: lines(7)}, stdout: []string{"a", "d", "g"}, stderr: []string{}, skipped: 4},
+		{name: "last", every: 3, mock: &CommandServiceMock{stdout: lines(5)}, stdout: []string{"a", "d", "e"}, stderr: []string{}, skipped: 2},
+		{name: "stream", every: 2, stream: true, mock: &CommandServiceMock{stdout: lines(4)}, stdout: []string{"a", "c", "d"}, stderr: []string{}, skipped: 1},
+		{name: "stderr", every: 10, mock: &CommandServiceMock{stdout: lines(3), stderr: "x\ny\nz"}, stdout: []string{"a", "c"}, stderr: []string{"x", "y", "z"}, skipped: 1},
+		{name: "every", every: 1, mock: &CommandServiceMock{stdout: lines(3)}, stdout: []string{"a", "b", "c"}, stderr: []string{}},
+		{name: "invalid", every: 0, err: errors.New("invalid sample rate: 0")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := []interface{}{WithSampleLines(tc.every), withCommandService(tc.mock)}
+			if tc.stream {
+				args = append(args, WithStreaming())
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			stdout, stderr := []string{}, []string{}
+			for event := range events {
+				stdout = append(stdout, event.Data().Stdout()...)
+				stderr = append(stderr, event.Data().Stderr()...)
+			}
+			state := <-cmd.Wait()
+			validateResult(tt, tc.stdout, stdout)
+			validateResult(tt, tc.stderr, stderr)
+			validateResult(tt, tc.skipped, state.Stats().SkippedLines)
+		})
+	}
+}
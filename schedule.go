@@ -0,0 +1,47 @@
+package command
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RunEvery executes spec immediately and then every interval plus a random
+// delay of up to jitter, sending each Result to the returned channel.
+// Executions never overlap: if a run takes longer than the interval the next
+// one starts right after it. The channel is closed when ctx is done.
+func RunEvery(ctx context.Context, spec Spec, interval, jitter time.Duration) <-chan *Result {
+	results := make(chan *Result)
+
+	go func() {
+		defer close(results)
+		for {
+			start := time.Now()
+			result, err := run(ctx, spec)
+			if err != nil {
+				result = &Result{Err: err}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case results <- result:
+			}
+
+			next := start.Add(interval)
+			if jitter > 0 {
+				next = next.Add(time.Duration(rand.Int63n(int64(jitter))))
+			}
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	return results
+}
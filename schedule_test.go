@@ -0,0 +1,47 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunEvery(t *testing.T) {
+	testCases := []struct {
+		name   string
+		spec   Spec
+		jitter time.Duration
+		expect []string
+		err    bool
+	}{
+		{name: "output", spec: Spec{Name: "echo", Args: []interface{}{withCommandService(&CommandServiceMock{stdout: "tick"})}}, expect: []string{"tick"}},
+		{name: "jitter", spec: Spec{Name: "echo", Args: []interface{}{withCommandService(&CommandServiceMock{stdout: "tick"})}}, jitter: time.Millisecond, expect: []string{"tick"}},
+		{name: "startError", spec: Spec{Name: "echo", Args: []interface{}{withCommandService(&CommandServiceMock{errStart: true})}}, err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			runs := 0
+			started := time.Now()
+			for result := range RunEvery(ctx, tc.spec, time.Millisecond, tc.jitter) {
+				// run n starts no earlier than (n-1) intervals after the first
+				if elapsed := time.Since(started); elapsed < time.Duration(runs)*time.Millisecond {
+					tt.Fatalf("run %d too early: %v", runs, elapsed)
+				}
+				validateBool(tt, tc.err, result.Err != nil)
+				if !tc.err {
+					validateResult(tt, tc.expect, result.Data.Stdout())
+					validateResult(tt, 0, result.State.ExitCode())
+				}
+				if runs++; runs == 3 {
+					cancel()
+				}
+			}
+			validateResult(tt, 3, runs)
+		})
+	}
+}
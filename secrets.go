@@ -0,0 +1,138 @@
+package command
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SecretProvider resolves secrets by key, e.g. from a vault or a cloud secret
+// manager.
+type SecretProvider interface {
+	Get(ctx context.Context, key string) (string, error)
+}
+
+// WithSecrets resolves secrets from provider when the command is executed.
+// mapping maps environment variable names to secret keys: every name is set
+// in the environment of the process and every occurrence of ${name} in the
+// arguments is replaced by the secret. Resolved secrets are redacted from
+// the command output.
+func WithSecrets(provider SecretProvider, mapping map[string]string) Option {
+
+	return func(c *Command) error {
+		c.record("WithSecrets", redactedKeys(mapping))
+		c.envFuncs = append(c.envFuncs, func(ctx context.Context) (map[string]string, error) {
+			vars := make(map[string]string, len(mapping))
+			for name, key := range mapping {
+				secret, err := provider.Get(ctx, key)
+				if err != nil {
+					return nil, err
+				}
+				vars[name] = secret
+				c.secrets.add(secret)
+				c.expandArgs(name, secret)
+			}
+			return vars, nil
+		})
+		return nil
+	}
+}
+
+// expandArgs replaces ${name} in the process arguments by value.
+func (c *Command) expandArgs(name, value string) {
+	cmd, ok := c.cmd.(*exec.Cmd)
+	if !ok {
+		return
+	}
+	ref := "${" + name + "}"
+	for i := 1; i < len(cmd.Args); i++ {
+		cmd.Args[i] = strings.ReplaceAll(cmd.Args[i], ref, value)
+	}
+}
+
+// secretSet holds the secrets and patterns which are redacted from the
+// output.
+type secretSet struct {
+	mu       sync.RWMutex
+	values   []string
+	patterns []*regexp.Regexp
+}
+
+// add registers secret unless it is empty or already registered, as the
+// secrets are resolved again for every execution.
+func (s *secretSet) add(secret string) {
+	if secret == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range s.values {
+		if v == secret {
+			return
+		}
+	}
+	s.values = append(s.values, secret)
+}
+
+func (s *secretSet) addPattern(re *regexp.Regexp) {
+	s.mu.Lock()
+	s.patterns = append(s.patterns, re)
+	s.mu.Unlock()
+}
+
+// redact replaces all registered secrets and pattern matches in text.
+func (s *secretSet) redact(text string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, secret := range s.values {
+		text = strings.ReplaceAll(text, secret, redacted)
+	}
+	for _, re := range s.patterns {
+		text = re.ReplaceAllLiteralString(text, redacted)
+	}
+	return text
+}
+
+// redacts reports whether the text between start and end overlaps a
+// registered secret or pattern match in text.
+func (s *secretSet) redacts(text string, start, end int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	overlaps := func(i, j int) bool {
+		return i < end && start < j
+	}
+	for _, secret := range s.values {
+		for i := 0; ; i++ {
+			j := strings.Index(text[i:], secret)
+			if j < 0 {
+				break
+			}
+			i += j
+			if overlaps(i, i+len(secret)) {
+				return true
+			}
+		}
+	}
+	for _, re := range s.patterns {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if overlaps(loc[0], loc[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactEnvValues replaces all registered secrets in the values of env.
+func (s *secretSet) redactEnvValues(env []string) []string {
+	out := make([]string, len(env))
+	for i, kv := range env {
+		if j := strings.IndexByte(kv, '='); j >= 0 {
+			kv = kv[:j+1] + s.redact(kv[j+1:])
+		}
+		out[i] = kv
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type secretProviderMock map[string]string
+
+func (m secretProviderMock) Get(ctx context.Context, key string) (string, error) {
+	if v, ok := m[key]; ok {
+		return v, nil
+	}
+	return "", errors.New("secret not found: " + key)
+}
+
+func TestSecretSetRedact(t *testing.T) {
+	testCases := []struct {
+		name    string
+		secrets []string
+		text    string
+		expect  string
+	}{
+		{name: "none", text: "s3cr3t", expect: "s3cr3t"},
+		{name: "single", secrets: []string{"s3cr3t"}, text: "token=s3cr3t s3cr3t", expect: "token=*** ***"},
+		{name: "multiple", secrets: []string{"a1", "", "b2"}, text: "a1:b2", expect: "***:***"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			var set secretSet
+			for _, s := range tc.secrets {
+				set.add(s)
+			}
+			validateResult(tt, tc.expect, set.redact(tc.text))
+		})
+	}
+}
+
+func TestSecretSetAdd(t *testing.T) {
+	var set secretSet
+	for i := 0; i < 3; i++ {
+		// the secrets are resolved again for every execution
+		set.add("a1")
+		set.add("b2")
+	}
+	validateResult(t, []string{"a1", "b2"}, set.values)
+}
+
+func TestCommandSecrets(t *testing.T) {
+	provider := secretProviderMock{"db/password": "hunter2"}
+	testCases := []struct {
+		name    string
+		mapping map[string]string
+		script  string
+		expect  []string
+		err     error
+	}{
+		{name: "env", mapping: map[string]string{"DB_PASSWORD": "db/password"}, script: "echo pw=$DB_PASSWORD; test $DB_PASSWORD = hunter2 && echo ok", expect: []string{"pw=***", "ok"}},
+		{name: "args", mapping: map[string]string{"PW": "db/password"}, script: "echo $0; test $0 = hunter2 && echo ok", expect: []string{"***", "ok"}},
+		{name: "notFound", mapping: map[string]string{"PW": "missing"}, err: errors.New("secret not found: missing")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", WithSecrets(provider, tc.mapping), WithFingerprint(), "-c", tc.script, "${PW}")
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			event := <-events
+			validateResult(tt, tc.expect, event.Data().Stdout())
+			state := <-cmd.Wait()
+			for _, kv := range state.Fingerprint().Env {
+				if kv == "DB_PASSWORD=hunter2" {
+					tt.Fatalf("secret not redacted in fingerprint: %s", kv)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEventSeqTime(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []interface{}
+		events int
+	}{
+		{name: "stream", args: []interface{}{WithStreaming()}, events: 4},
+		{name: "result", events: 1},
+		{name: "batched", args: []interface{}{WithStreaming(), WithBatching(2, 0)}, events: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", append([]interface{}{withCommandService(&CommandServiceMock{stdout: "1\n2\n3\n4"})}, tc.args...)...)
+			validateError(tt, nil, err)
+			start := time.Now()
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			var seq uint64
+			var last time.Time
+			n := 0
+			for event := range events {
+				validateBool(tt, true, event.Seq() > seq)
+				validateBool(tt, true, !event.Time().Before(last) && !event.Time().Before(start))
+				seq, last = event.Seq(), event.Time()
+				n++
+			}
+			<-cmd.Wait()
+			validateResult(tt, tc.events, n)
+		})
+	}
+}
+
+func TestEventReadTime(t *testing.T) {
+	cmd, err := NewCommand(context.Background(), "sh", withCommandService(&CommandServiceMock{stdout: "1\n2"}), WithStreaming(), WithEventBuffer(10))
+	validateError(t, nil, err)
+	start := time.Now()
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	<-cmd.Wait()
+	consumed := time.Now()
+	first, second := <-events, <-events
+	// the time is taken when the line is read, not when it is consumed
+	validateBool(t, true, !first.Time().Before(start) && !second.Time().Before(first.Time()))
+	validateBool(t, true, !second.Time().After(consumed))
+}
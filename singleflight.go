@@ -0,0 +1,66 @@
+package command
+
+import (
+	"context"
+	"sync"
+)
+
+// Group suppresses concurrent duplicate executions. Concurrent calls of Do
+// for specs with the same key share a single execution and receive the same
+// Result. The zero value is ready to use.
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is an in-progress or completed execution of a Group.
+type flight struct {
+	done   chan struct{}
+	result *Result
+	dups   int
+}
+
+// Do executes spec unless an execution with the same key is already in
+// progress, in which case it waits for that execution. The execution runs
+// with the context of the first caller; other callers stop waiting when
+// their own ctx is done. shared reports whether the Result was given to
+// multiple callers. Specs with Options but without a Key are never shared.
+func (g *Group) Do(ctx context.Context, spec Spec) (result *Result, shared bool) {
+	key, ok := spec.key()
+	if !ok {
+		result, err := run(ctx, spec)
+		if err != nil {
+			result = &Result{Err: err}
+		}
+		return result, false
+	}
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+	if f, ok := g.calls[key]; ok {
+		f.dups++
+		g.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return &Result{Err: ctx.Err()}, false
+		case <-f.done:
+			return f.result, true
+		}
+	}
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	result, err := run(ctx, spec)
+	if err != nil {
+		result = &Result{Err: err}
+	}
+	f.result = result
+	g.mu.Lock()
+	delete(g.calls, key)
+	shared = f.dups > 0
+	g.mu.Unlock()
+	close(f.done)
+	return result, shared
+}
@@ -0,0 +1,89 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSpecKey(t *testing.T) {
+	testCases := []struct {
+		name   string
+		a, b   Spec
+		expect bool
+	}{
+		{name: "equal", a: Spec{Name: "sh", Args: []interface{}{"-c", "exit"}}, b: Spec{Name: "sh", Args: []interface{}{"-c", "exit"}}, expect: true},
+		{name: "options", a: Spec{Name: "sh", Args: []interface{}{WithDir("/tmp"), "-c"}}, b: Spec{Name: "sh", Args: []interface{}{WithDir("/"), "-c"}}},
+		{name: "optionsWithoutKey", a: Spec{Name: "sh", Args: []interface{}{WithDir("/tmp")}}, b: Spec{Name: "sh", Args: []interface{}{WithDir("/tmp")}}},
+		{name: "optionsWithKey", a: Spec{Name: "sh", Args: []interface{}{WithDir("/tmp")}, Key: "k"}, b: Spec{Name: "sh", Args: []interface{}{WithDir("/")}, Key: "k"}, expect: true},
+		{name: "args", a: Spec{Name: "sh", Args: []interface{}{"-c", "exit 1"}}, b: Spec{Name: "sh", Args: []interface{}{"-c", "exit"}}},
+		{name: "split", a: Spec{Name: "sh", Args: []interface{}{"a b"}}, b: Spec{Name: "sh", Args: []interface{}{"a", "b"}}},
+		{name: "explicit", a: Spec{Name: "sh", Key: "k"}, b: Spec{Name: "bash", Key: "k"}, expect: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			a, okA := tc.a.key()
+			b, okB := tc.b.key()
+			validateBool(tt, tc.expect, okA && okB && a == b)
+		})
+	}
+}
+
+// blockingServiceMock counts its starts and blocks in Wait until released.
+type blockingServiceMock struct {
+	CommandServiceMock
+	starts  int32
+	release chan struct{}
+}
+
+func (m *blockingServiceMock) Start() error {
+	atomic.AddInt32(&m.starts, 1)
+	return m.CommandServiceMock.Start()
+}
+
+func (m *blockingServiceMock) Wait() error {
+	<-m.release
+	return m.CommandServiceMock.Wait()
+}
+
+func TestGroupDo(t *testing.T) {
+	mock := &blockingServiceMock{CommandServiceMock: CommandServiceMock{stdout: "done\n"}, release: make(chan struct{})}
+	spec := Spec{Name: "sh", Args: []interface{}{withCommandService(mock)}, Key: "k"}
+
+	var g Group
+	var wg sync.WaitGroup
+	results := make([]*Result, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = g.Do(context.Background(), spec)
+		}(i)
+	}
+	// release the command once all other callers wait for it
+	for dups := 0; dups < len(results)-1; {
+		runtime.Gosched()
+		g.mu.Lock()
+		for _, f := range g.calls {
+			dups = f.dups
+		}
+		g.mu.Unlock()
+	}
+	close(mock.release)
+	wg.Wait()
+
+	validateResult(t, int32(1), atomic.LoadInt32(&mock.starts))
+	for _, r := range results {
+		validateResult(t, results[0], r)
+		validateResult(t, []string{"done"}, r.Data.Stdout())
+	}
+
+	result, shared := g.Do(context.Background(), Spec{Name: "sh", Args: []interface{}{withCommandService(&CommandServiceMock{errStart: true})}, Key: "err"})
+	validateBool(t, false, shared)
+	validateBool(t, true, result.Err != nil)
+}
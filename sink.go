@@ -0,0 +1,178 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+)
+
+// WithStdoutWriter writes the stdout output to w instead of delivering it
+// as events or collecting it in the result (see WithWriterTee), e.g. to run
+// a command with Run without reading its events. Lines are written with
+// their line terminator, chunks (see WithChunkStreaming) as they are.
+// Writes are serialized, so the same writer can be passed to
+// WithStderrWriter. After the last line, w is flushed if it has a Flush()
+// error method and closed if it is an io.Closer other than os.Stdout and
+// os.Stderr. A write error is reported like a command error, the remaining
+// output is delivered as usual.
+func WithStdoutWriter(w io.Writer) Option {
+
+	return func(c *Command) error {
+		c.record("WithStdoutWriter", fmt.Sprintf("%T", w))
+		if w == nil {
+			return fmt.Errorf("writer cannot be nil")
+		}
+		c.writerSinks().stdout = w
+		return nil
+	}
+}
+
+// WithStderrWriter writes the stderr output to w (see WithStdoutWriter).
+func WithStderrWriter(w io.Writer) Option {
+
+	return func(c *Command) error {
+		c.record("WithStderrWriter", fmt.Sprintf("%T", w))
+		if w == nil {
+			return fmt.Errorf("writer cannot be nil")
+		}
+		c.writerSinks().stderr = w
+		return nil
+	}
+}
+
+// WithWriterTee delivers the output written to the writers of
+// WithStdoutWriter and WithStderrWriter as events, too.
+func WithWriterTee() Option {
+
+	return func(c *Command) error {
+		c.record("WithWriterTee")
+		c.writerSinks().tee = true
+		return nil
+	}
+}
+
+// WithTee mirrors the stdout and stderr output to the given writers, e.g.
+// os.Stdout and os.Stderr, while events and results work unchanged. A nil
+// writer leaves its stream alone. It is a shorthand for WithStdoutWriter,
+// WithStderrWriter and WithWriterTee, so the writers are flushed and closed
+// the same way.
+func WithTee(stdout, stderr io.Writer) Option {
+
+	return func(c *Command) error {
+		c.record("WithTee", fmt.Sprintf("%T", stdout), fmt.Sprintf("%T", stderr))
+		if stdout == nil && stderr == nil {
+			return fmt.Errorf("writer cannot be nil")
+		}
+		s := c.writerSinks()
+		if stdout != nil {
+			s.stdout = stdout
+		}
+		if stderr != nil {
+			s.stderr = stderr
+		}
+		s.tee = true
+		return nil
+	}
+}
+
+// sinks writes the output of a command to writers. It is only used by the
+// goroutine which forwards the events.
+type sinks struct {
+	stdout io.Writer
+	stderr io.Writer
+	tee    bool
+	err    error
+
+	// stamp returns the timestamp prefix of a line (see
+	// WithSinkTimestamps)
+	stamp func(time.Time) string
+
+	// partial is set per stream if the last line was partial
+	partial [2]bool
+}
+
+func (c *Command) writerSinks() *sinks {
+	if c.sinks == nil {
+		c.sinks = &sinks{}
+	}
+	return c.sinks
+}
+
+// write writes the data of evt to the writers of its streams and reports
+// whether evt has to be delivered as usual.
+func (s *sinks) write(evt Event) bool {
+	if s.err != nil {
+		return true
+	}
+	data := evt.Data()
+	keep := s.tee
+	for n, stream := range []struct {
+		name  string
+		w     io.Writer
+		lines []string
+	}{{StreamStdout, s.stdout, data.Stdout()}, {StreamStderr, s.stderr, data.Stderr()}} {
+		if len(stream.lines) == 0 {
+			continue
+		}
+		if stream.w == nil {
+			keep = true
+			continue
+		}
+		prefix := ""
+		if s.stamp != nil && data.Bytes() == nil && !s.partial[n] {
+			prefix = s.stamp(evt.Time())
+		}
+		s.partial[n] = data.Partial()
+		if err := writeData(stream.w, data, stream.lines, prefix); err != nil {
+			s.err = fmt.Errorf("write %s: %w", stream.name, err)
+			return true
+		}
+	}
+	return keep
+}
+
+func writeData(w io.Writer, data Data, lines []string, prefix string) error {
+	if chunk := data.Bytes(); chunk != nil {
+		_, err := w.Write(chunk)
+		return err
+	}
+	terminator := "\n"
+	if data.Partial() {
+		terminator = ""
+	} else if data.Progress() {
+		terminator = "\r"
+	}
+	for _, line := range lines {
+		if _, err := io.WriteString(w, prefix+line+terminator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish flushes and closes the writers.
+func (s *sinks) finish() {
+	writers := []io.Writer{s.stdout, s.stderr}
+	if s.stdout != nil && reflect.TypeOf(s.stdout).Comparable() && s.stdout == s.stderr {
+		writers = writers[:1]
+	}
+	for _, w := range writers {
+		if w == nil {
+			continue
+		}
+		var err error
+		if f, ok := w.(interface{ Flush() error }); ok {
+			err = f.Flush()
+		}
+		if closer, ok := w.(io.Closer); ok && w != io.Writer(os.Stdout) && w != io.Writer(os.Stderr) {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil && s.err == nil {
+			s.err = fmt.Errorf("close writer: %w", err)
+		}
+	}
+}
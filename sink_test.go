@@ -0,0 +1,209 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type closingBuffer struct {
+	bytes.Buffer
+	closed int
+}
+
+func (b *closingBuffer) Close() error {
+	b.closed++
+	return nil
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("errWrite")
+}
+
+func TestWriterSinks(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      []interface{}
+		stdout    string
+		stderr    string
+		events    []string
+		errEvents []string
+		err       error
+		combine   bool
+	}{
+		{
+			name:      "stream",
+			args:      []interface{}{WithStreaming()},
+			stdout:    "1\n3\n",
+			stderr:    "2\n",
+			events:    []string{},
+			errEvents: []string{},
+		},
+		{
+			name:      "result",
+			stdout:    "1\n3\n",
+			stderr:    "2\n",
+			events:    []string{},
+			errEvents: []string{},
+			err:       errors.New("no error"),
+		},
+		{
+			name:      "tee",
+			args:      []interface{}{WithStreaming(), WithWriterTee()},
+			stdout:    "1\n3\n",
+			stderr:    "2\n",
+			events:    []string{"1", "3"},
+			errEvents: []string{"2"},
+		},
+		{
+			name:      "sameWriter",
+			args:      []interface{}{WithStreaming()},
+			stdout:    "1\n2\n3\n",
+			events:    []string{},
+			errEvents: []string{},
+			combine:   true,
+		},
+		{
+			name:      "chunks",
+			args:      []interface{}{WithChunkStreaming(64)},
+			stdout:    "1\n3\n",
+			stderr:    "2\n",
+			events:    []string{},
+			errEvents: []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			stdout := &closingBuffer{}
+			stderr := &closingBuffer{}
+			args := append([]interface{}{withCommandService(&CommandServiceMock{stdout: "1\n3\n", stderr: "2\n"})}, tc.args...)
+			if tc.combine {
+				stderr = stdout
+			}
+			args = append(args, WithStdoutWriter(stdout), WithStderrWriter(stderr))
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			lines := []string{}
+			errLines := []string{}
+			var resultErr error
+			for event := range events {
+				lines = append(lines, event.Data().Stdout()...)
+				errLines = append(errLines, event.Data().Stderr()...)
+				resultErr = event.Error()
+			}
+			state := <-cmd.Wait()
+			validateError(tt, nil, state.Error())
+			validateError(tt, tc.err, resultErr)
+			validateResult(tt, tc.events, lines)
+			validateResult(tt, tc.errEvents, errLines)
+			validateResult(tt, 1, stdout.closed)
+			if tc.combine {
+				// the streams are written in the order they were read
+				written := strings.SplitAfter(stdout.String(), "\n")
+				sort.Strings(written)
+				validateResult(tt, tc.stdout, strings.Join(written, ""))
+				return
+			}
+			validateResult(tt, tc.stdout, stdout.String())
+			validateResult(tt, tc.stderr, stderr.String())
+			validateResult(tt, 1, stderr.closed)
+		})
+	}
+}
+
+func TestWriterSinkError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		stream bool
+		err    error
+	}{
+		{name: "stream", stream: true, err: errors.New("write stdout: errWrite")},
+		{name: "result", err: errors.New("write stdout: errWrite")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := []interface{}{withCommandService(&CommandServiceMock{stdout: "1\n2"}), WithStdoutWriter(failingWriter{})}
+			if tc.stream {
+				args = append(args, WithStreaming())
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			lines := []string{}
+			var lastErr error
+			for event := range events {
+				if event.Error() != nil {
+					lastErr = event.Error()
+				}
+				if event.Error() == nil || !tc.stream {
+					lines = append(lines, event.Data().Stdout()...)
+				}
+			}
+			validateError(tt, tc.err, lastErr)
+			validateResult(tt, []string{"1", "2"}, lines)
+		})
+	}
+}
+
+func TestWriterSinkNil(t *testing.T) {
+	_, err := NewCommand(context.Background(), "true", WithStdoutWriter(nil))
+	validateError(t, errors.New("writer cannot be nil"), err)
+	_, err = NewCommand(context.Background(), "true", WithStderrWriter(nil))
+	validateError(t, errors.New("writer cannot be nil"), err)
+}
+
+func TestTee(t *testing.T) {
+	testCases := []struct {
+		name   string
+		stdout bool
+		stderr bool
+		result []string
+		err    error
+	}{
+		{name: "both", stdout: true, stderr: true, result: []string{"1", "2"}},
+		{name: "stdout", stdout: true, result: []string{"1", "2"}},
+		{name: "none", err: errors.New("writer cannot be nil")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := []interface{}{withCommandService(&CommandServiceMock{stdout: "1", stderr: "2"})}
+			switch {
+			case tc.stdout && tc.stderr:
+				args = append(args, WithTee(&stdout, &stderr))
+			case tc.stdout:
+				args = append(args, WithTee(&stdout, nil))
+			default:
+				args = append(args, WithTee(nil, nil))
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			result := <-events
+			for range events {
+			}
+			validateResult(tt, tc.result, result.Data().Out())
+			validateResult(tt, "1\n", stdout.String())
+			if tc.stderr {
+				validateResult(tt, "2\n", stderr.String())
+			} else {
+				validateResult(tt, "", stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sync/atomic"
+)
+
+// SpillFile holds output records which were written to disk because of
+// WithSpill, each terminated by Delim. The file is removed automatically
+// once the context of the command is done or the SpillFile is garbage
+// collected, unless Keep is called.
+type SpillFile struct {
+	Path  string
+	Lines int64
+
+	// Delim terminates every record, "\n" or NUL with WithNulDelimited.
+	Delim byte
+
+	kept atomic.Bool
+}
+
+func newSpillFile(path string, delim byte) *SpillFile {
+	f := &SpillFile{Path: path, Delim: delim}
+	runtime.SetFinalizer(f, (*SpillFile).release)
+	return f
+}
+
+// Keep disables the automatic removal of the file, which is then up to the
+// caller.
+func (f *SpillFile) Keep() {
+	f.kept.Store(true)
+}
+
+// Open returns a reader of the spilled records. Unless Keep was called, the
+// file is removed once the reader is closed.
+func (f *SpillFile) Open() (io.ReadCloser, error) {
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &spillReader{File: file, spill: f}, nil
+}
+
+// Remove removes the file.
+func (f *SpillFile) Remove() error {
+	return os.Remove(f.Path)
+}
+
+// release removes the file unless it is kept.
+func (f *SpillFile) release() error {
+	if f.kept.Load() {
+		return nil
+	}
+	if err := f.Remove(); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// spillReader releases the file on Close.
+type spillReader struct {
+	*os.File
+	spill *SpillFile
+}
+
+func (r *spillReader) Close() error {
+	err := r.File.Close()
+	if rmErr := r.spill.release(); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// WithSpill keeps at most threshold bytes of output in memory in
+// non-streaming mode and writes the remaining lines to temporary files in
+// dir, or the default directory for temporary files if dir is empty. The
+// files are reported by State.Spilled. They are removed once they are read
+// with SpillFile.Open, by SpillFile.Remove, by Command.Close, or
+// automatically once the context of the command is done or the SpillFile is
+// no longer referenced, unless SpillFile.Keep is called. If a file can't be
+// written, the lines are kept in memory and the error of the result event
+// reports the failure.
+func WithSpill(threshold int, dir string) Option {
+
+	return func(c *Command) error {
+		c.record("WithSpill", threshold, dir)
+		if threshold < 0 {
+			return fmt.Errorf("invalid spill threshold: %d", threshold)
+		}
+		c.spill = &spiller{threshold: int64(threshold), dir: dir}
+		return nil
+	}
+}
+
+// spiller collects the output of a command in memory and on disk. It is only
+// used by the goroutine which forwards the events.
+type spiller struct {
+	threshold int64
+	dir       string
+	delim     byte
+	bytes     int64
+	stdout    *spillWriter
+	stderr    *spillWriter
+	err       error
+}
+
+type spillWriter struct {
+	file  *os.File
+	w     *bufio.Writer
+	spill *SpillFile
+}
+
+// add appends lines to mem until the threshold is exceeded and writes them
+// to the spill file of the stream afterwards.
+func (s *spiller) add(lines []string, isStderr bool, mem *[]string) {
+	for _, line := range lines {
+		if s.bytes+int64(len(line)) <= s.threshold || s.err != nil {
+			s.bytes += int64(len(line))
+			*mem = append(*mem, line)
+			continue
+		}
+		s.bytes = s.threshold + 1
+		w, err := s.writer(isStderr)
+		if err == nil {
+			_, err = w.w.WriteString(line)
+		}
+		if err == nil {
+			err = w.w.WriteByte(s.delim)
+		}
+		if err != nil {
+			s.err = fmt.Errorf("spill failed: %w", err)
+			*mem = append(*mem, line)
+			continue
+		}
+		w.spill.Lines++
+	}
+}
+
+// writer returns the spill writer of the stream, creating the file if
+// needed.
+func (s *spiller) writer(isStderr bool) (*spillWriter, error) {
+	w := &s.stdout
+	pattern := "stdout-*"
+	if isStderr {
+		w, pattern = &s.stderr, "stderr-*"
+	}
+	if *w == nil {
+		file, err := ioutil.TempFile(s.dir, pattern)
+		if err != nil {
+			return nil, err
+		}
+		*w = &spillWriter{file: file, w: bufio.NewWriter(file), spill: newSpillFile(file.Name(), s.delim)}
+	}
+	return *w, nil
+}
+
+// finish flushes and closes the spill files. They are released once ctx
+// is done.
+func (s *spiller) finish(ctx context.Context) {
+	for _, w := range []*spillWriter{s.stdout, s.stderr} {
+		if w == nil {
+			continue
+		}
+		err := w.w.Flush()
+		if closeErr := w.file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil && s.err == nil {
+			s.err = fmt.Errorf("spill failed: %w", err)
+		}
+	}
+	if (s.stdout != nil || s.stderr != nil) && ctx.Done() != nil {
+		go func() {
+			<-ctx.Done()
+			s.release()
+		}()
+	}
+}
+
+// files returns the spill files of stdout and stderr.
+func (s *spiller) files() (stdout, stderr *SpillFile) {
+	if s == nil {
+		return nil, nil
+	}
+	return s.stdout.spillFile(), s.stderr.spillFile()
+}
+
+func (w *spillWriter) spillFile() *SpillFile {
+	if w == nil {
+		return nil
+	}
+	return w.spill
+}
+
+// release removes the spill files which are not kept.
+func (s *spiller) release() {
+	stdout, stderr := s.files()
+	for _, f := range []*SpillFile{stdout, stderr} {
+		if f != nil {
+			f.release()
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	validateError(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		name      string
+		mock      *CommandServiceMock
+		threshold int
+		dir       string
+		stdout    []string
+		stderr    []string
+		spillOut  string
+		spillErr  string
+		resultErr error
+		err       error
+	}{
+		{name: "none", mock: &CommandServiceMock{stdout: "1\n2\n3"}, threshold: 100, stdout: []string{"1", "2", "3"}, stderr: []string{}, resultErr: errors.New("no error")},
+		{
+			name:      "spilled",
+			mock:      &CommandServiceMock{stdout: "1\n2\n3\n4\n5"},
+			threshold: 3,
+			stdout:    []string{"1", "2", "3"},
+			stderr:    []string{},
+			spillOut:  "4\n5\n",
+			resultErr: errors.New("no error"),
+		},
+		{
+			name:      "spilledStderr",
+			mock:      &CommandServiceMock{stderr: "1\n2\n3\n4\n5"},
+			threshold: 3,
+			stdout:    []string{},
+			stderr:    []string{"1", "2", "3"},
+			spillErr:  "4\n5\n",
+			resultErr: errors.New("no error"),
+		},
+		{name: "failed", mock: &CommandServiceMock{stdout: "1\n2\n3"}, threshold: 1, dir: filepath.Join(dir, "missing"), stdout: []string{"1", "2", "3"}, stderr: []string{}},
+		{name: "invalid", threshold: -1, err: errors.New("invalid spill threshold: -1")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			spillDir := tc.dir
+			if spillDir == "" {
+				spillDir = dir
+			}
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(tc.mock), WithSpill(tc.threshold, spillDir))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			event := <-events
+			validateResult(tt, tc.stdout, event.Data().Stdout())
+			validateResult(tt, tc.stderr, event.Data().Stderr())
+			if tc.resultErr != nil {
+				validateError(tt, tc.resultErr, event.Error())
+			} else {
+				validateBool(tt, true, event.Error() != nil && event.Error().Error() != "no error")
+			}
+			stdout, stderr := (<-cmd.Wait()).Spilled()
+			for _, f := range []struct {
+				file   *SpillFile
+				expect string
+			}{{stdout, tc.spillOut}, {stderr, tc.spillErr}} {
+				validateBool(tt, f.expect != "", f.file != nil)
+				if f.file == nil {
+					continue
+				}
+				r, err := f.file.Open()
+				validateError(tt, nil, err)
+				data, err := ioutil.ReadAll(r)
+				validateError(tt, nil, err)
+				validateResult(tt, f.expect, string(data))
+				validateError(tt, nil, r.Close())
+				_, err = os.Stat(f.file.Path)
+				validateBool(tt, true, os.IsNotExist(err))
+			}
+		})
+	}
+}
+
+func TestSpillClose(t *testing.T) {
+	cmd, err := NewCommand(context.Background(), "sh", "-c", "seq 5", WithSpill(0, ""))
+	validateError(t, nil, err)
+	_, err = cmd.Execute()
+	validateError(t, nil, err)
+	stdout, _ := (<-cmd.Wait()).Spilled()
+	validateResult(t, int64(5), stdout.Lines)
+	validateError(t, nil, cmd.Close())
+	_, err = os.Stat(stdout.Path)
+	validateBool(t, true, os.IsNotExist(err))
+}
+
+func TestSpillNulDelimited(t *testing.T) {
+	mock := &CommandServiceMock{stdout: "1\x00a\nb\x00c\x00"}
+	cmd, err := NewCommand(context.Background(), "sh", withCommandService(mock), WithNulDelimited(), WithSpill(1, ""))
+	validateError(t, nil, err)
+	_, err = cmd.Execute()
+	validateError(t, nil, err)
+	stdout, _ := (<-cmd.Wait()).Spilled()
+	validateResult(t, int64(2), stdout.Lines)
+	validateResult(t, byte(0), stdout.Delim)
+	data, err := ioutil.ReadFile(stdout.Path)
+	validateError(t, nil, err)
+	validateResult(t, "a\nb\x00c\x00", string(data))
+	validateError(t, nil, cmd.Close())
+}
+
+func TestSpillRelease(t *testing.T) {
+	testCases := []struct {
+		name    string
+		keep    bool
+		removed bool
+	}{
+		{name: "removed", removed: true},
+		{name: "kept", keep: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			mock := &CommandServiceMock{stdout: "1\n2\n3"}
+			cmd, err := NewCommand(ctx, "sh", withCommandService(mock), WithSpill(1, ""))
+			validateError(tt, nil, err)
+			_, err = cmd.Execute()
+			validateError(tt, nil, err)
+			stdout, _ := (<-cmd.Wait()).Spilled()
+			defer os.Remove(stdout.Path)
+			if tc.keep {
+				stdout.Keep()
+			}
+			cancel()
+			if tc.keep {
+				// what the context watcher does
+				cmd.spill.release()
+			}
+			for i := 0; i < 100; i++ {
+				if _, err = os.Stat(stdout.Path); !tc.removed || os.IsNotExist(err) {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			validateBool(tt, tc.removed, os.IsNotExist(err))
+			validateError(tt, nil, cmd.Close())
+			_, err = os.Stat(stdout.Path)
+			validateBool(tt, tc.removed, os.IsNotExist(err))
+		})
+	}
+}
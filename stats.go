@@ -0,0 +1,119 @@
+package command
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Stats holds output statistics of a command execution.
+type Stats struct {
+	// FirstByte is the time from process start to the first byte of output
+	// on any stream. It is zero if the command produced no output.
+	FirstByte time.Duration
+
+	StdoutBytes int64
+	StderrBytes int64
+	StdoutLines int64
+	StderrLines int64
+
+	// SkippedLines is the number of stdout lines which were not forwarded
+	// because of WithSampleLines.
+	SkippedLines int64
+
+	// FilteredLines is the number of lines which were dropped by the line
+	// filter (see WithLineFilter).
+	FilteredLines int64
+
+	// DroppedLines is the number of lines which were dropped because of the
+	// overflow policy (see WithOverflowPolicy).
+	DroppedLines int64
+
+	// TruncatedLines and TruncatedBytes count the output which was dropped
+	// because of WithMaxOutputLines or WithMaxOutputBytes.
+	TruncatedLines int64
+	TruncatedBytes int64
+}
+
+// statsRecorder collects Stats concurrently from the stream readers.
+type statsRecorder struct {
+	mu    sync.Mutex
+	start time.Time
+	stats Stats
+}
+
+func (r *statsRecorder) started(t time.Time) {
+	r.mu.Lock()
+	r.start = t
+	r.mu.Unlock()
+}
+
+func (r *statsRecorder) addBytes(n int, isStderr bool) {
+	if n <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stats.FirstByte == 0 && r.stats.StdoutBytes == 0 && r.stats.StderrBytes == 0 {
+		r.stats.FirstByte = time.Since(r.start)
+	}
+	if isStderr {
+		r.stats.StderrBytes += int64(n)
+	} else {
+		r.stats.StdoutBytes += int64(n)
+	}
+}
+
+func (r *statsRecorder) addLine(isStderr bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if isStderr {
+		r.stats.StderrLines++
+	} else {
+		r.stats.StdoutLines++
+	}
+}
+
+func (r *statsRecorder) addSkipped(n int64) {
+	r.mu.Lock()
+	r.stats.SkippedLines += n
+	r.mu.Unlock()
+}
+
+func (r *statsRecorder) addFiltered() {
+	r.mu.Lock()
+	r.stats.FilteredLines++
+	r.mu.Unlock()
+}
+
+func (r *statsRecorder) addDropped() {
+	r.mu.Lock()
+	r.stats.DroppedLines++
+	r.mu.Unlock()
+}
+
+func (r *statsRecorder) addTruncated(d Data) {
+	r.mu.Lock()
+	r.stats.TruncatedLines += int64(d.StdoutLines() + d.StderrLines())
+	r.stats.TruncatedBytes += int64(d.StdoutBytes() + d.StderrBytes())
+	r.mu.Unlock()
+}
+
+func (r *statsRecorder) snapshot() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// countingReader reports every read to a statsRecorder.
+type countingReader struct {
+	r        io.Reader
+	rec      *statsRecorder
+	isStderr bool
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.rec.addBytes(n, c.isStderr)
+	return n, err
+}
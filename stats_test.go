@@ -0,0 +1,60 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCommandStats(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      *CommandServiceMock
+		expect    Stats
+		firstByte bool
+	}{
+		{
+			name:      "output",
+			mock:      &CommandServiceMock{stdout: "a\nbb\n", stderr: "ccc"},
+			expect:    Stats{StdoutBytes: 5, StderrBytes: 3, StdoutLines: 2, StderrLines: 1},
+			firstByte: true,
+		},
+		{
+			name:   "noOutput",
+			mock:   &CommandServiceMock{},
+			expect: Stats{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(tc.mock))
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			for range events {
+			}
+			got := (<-cmd.Wait()).Stats()
+			validateBool(tt, tc.firstByte, got.FirstByte > 0)
+			got.FirstByte = 0
+			validateResult(tt, tc.expect, got)
+		})
+	}
+}
+
+func TestCommandStateTimes(t *testing.T) {
+	cmd, err := NewCommand(context.Background(), "sh", withCommandService(&CommandServiceMock{stdout: "1"}))
+	validateError(t, nil, err)
+	before := time.Now()
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	for range events {
+	}
+	state := <-cmd.Wait()
+	validateBool(t, true, !state.StartTime().Before(before))
+	validateBool(t, true, !time.Now().Before(state.EndTime()))
+	validateResult(t, state.EndTime().Sub(state.StartTime()), state.Duration())
+	validateBool(t, true, state.Duration() >= 0)
+}
@@ -0,0 +1,59 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// ExecuteStatus runs the command and returns its exit code. Unlike Execute
+// no pipes or goroutines are set up for the output, which is discarded, so
+// it is cheap enough for probes run at high frequency. A non-zero exit code
+// is not an error. The process is killed if ctx or the command's context is
+// done, in which case the context error (or a *CancelError) is returned
+// together with exit code -1. Wait must not be called after ExecuteStatus.
+func (c *Command) ExecuteStatus(ctx context.Context) (int, error) {
+	if err := c.cancelError(); err != nil {
+		return -1, err
+	}
+	if err := c.acquireBudget(); err != nil {
+		return -1, err
+	}
+	defer c.cleanup()
+	if err := c.applyEnv(); err != nil {
+		return -1, err
+	}
+	if err := c.startProcess(); err != nil {
+		return -1, err
+	}
+	c.watchExit()
+	exited := make(chan struct{})
+	if cmd, ok := c.cmd.(*exec.Cmd); ok {
+		go func() {
+			select {
+			case <-exited:
+			case <-ctx.Done():
+				c.kill(cmd)
+			}
+		}()
+	}
+	err := c.cmd.Wait()
+	close(exited)
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return -1, err
+	}
+	if err := c.cancelError(); err != nil {
+		return -1, err
+	}
+	if err := ctx.Err(); err != nil {
+		return -1, contextError(err)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return -1, contextError(err)
+	}
+	return c.processState.ExitCode(), nil
+}
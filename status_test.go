@@ -0,0 +1,38 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCommandExecuteStatus(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []interface{}
+		timeout time.Duration
+		expect  int
+		err     error
+	}{
+		{name: "success", args: []interface{}{withCommandService(&CommandServiceMock{stdout: "ignored"})}, expect: 0},
+		{name: "exitCode", args: []interface{}{"-c", "exit 3"}, expect: 3},
+		{name: "timeout", args: []interface{}{"-c", "exec sleep 10"}, timeout: time.Millisecond, expect: -1, err: context.DeadlineExceeded},
+		{name: "errStart", args: []interface{}{withCommandService(&CommandServiceMock{errStart: true})}, expect: -1, err: errors.New("errStart")},
+		{name: "errWait", args: []interface{}{withCommandService(&CommandServiceMock{errWait: true})}, expect: -1, err: errors.New("errWait")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", tc.args...)
+			validateError(tt, nil, err)
+			ctx, cancel := createTestContext(tc.timeout)
+			defer cancel()
+			code, err := cmd.ExecuteStatus(ctx)
+			validateError(tt, tc.err, err)
+			validateResult(tt, tc.expect, code)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"io"
+	"os/exec"
+)
+
+// WithStdin connects r to the standard input of the command. EOF is delivered
+// to the process when r is exhausted. If r is not an *os.File, the final
+// state is only available once r has been read to the end or the process
+// exited.
+func WithStdin(r io.Reader) Option {
+
+	return func(c *Command) error {
+		c.record("WithStdin", "reader")
+		c.configurers = append(c.configurers, func(cmd *exec.Cmd) error {
+			cmd.Stdin = r
+			return nil
+		})
+		return nil
+	}
+}
+
+// StdinWriter returns a writer connected to the standard input of the
+// command, so interactive tools can be driven while their events are read.
+// It must be called before Execute. Closing the writer delivers EOF to the
+// process; it is closed automatically once the process exited.
+func (c *Command) StdinWriter() (io.WriteCloser, error) {
+	return c.cmd.StdinPipe()
+}
+
+// WithStdinLines writes every value received from lines to the standard
+// input of the command, followed by a newline. Stdin is closed when lines is
+// closed, so both directions of the command can be driven from select loops.
+func WithStdinLines(lines <-chan string) Option {
+
+	return func(c *Command) error {
+		c.record("WithStdinLines", "channel")
+		c.stdinLines = lines
+		return nil
+	}
+}
+
+// feedLines writes the stdin lines to w until the channel is closed, the
+// output has been read completely or the context is done.
+func (c *Command) feedLines(w io.WriteCloser) {
+	defer w.Close()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-c.readDone:
+			return
+		case line, ok := <-c.stdinLines:
+			if !ok {
+				return
+			}
+			if _, err := io.WriteString(w, line+"\n"); err != nil {
+				return
+			}
+		}
+	}
+}
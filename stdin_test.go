@@ -0,0 +1,113 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandWithStdin(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []interface{}
+		expect []string
+	}{
+		{name: "grep", args: []interface{}{"grep", "b", WithStdin(strings.NewReader("a\nb\nab\n"))}, expect: []string{"b", "ab"}},
+		{name: "eof", args: []interface{}{"wc", "-l", WithStdin(strings.NewReader("1\n2\n"))}, expect: []string{"2"}},
+		{name: "empty", args: []interface{}{"cat", WithStdin(strings.NewReader(""))}, expect: []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), tc.args[0].(string), tc.args[1:]...)
+			validateError(tt, nil, err)
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			event := <-events
+			<-cmd.Wait()
+			got := []string{}
+			for _, line := range event.Data().Stdout() {
+				got = append(got, strings.TrimSpace(line))
+			}
+			validateResult(tt, tc.expect, got)
+		})
+	}
+}
+
+func TestCommandStdinWriter(t *testing.T) {
+	cmd, err := NewCommand(context.Background(), "cat", WithStreaming())
+	validateError(t, nil, err)
+	stdin, err := cmd.StdinWriter()
+	validateError(t, nil, err)
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+
+	// every line is echoed before the next one is written
+	for _, line := range []string{"one", "two"} {
+		_, err = io.WriteString(stdin, line+"\n")
+		validateError(t, nil, err)
+		event := <-events
+		validateResult(t, []string{line}, event.Data().Stdout())
+	}
+	validateError(t, nil, stdin.Close())
+	for range events {
+	}
+	validateResult(t, 0, (<-cmd.Wait()).ExitCode())
+
+	_, err = cmd.StdinWriter()
+	validateBool(t, true, err != nil)
+}
+
+func TestCommandStdinWriterService(t *testing.T) {
+	mock := &CommandServiceMock{}
+	cmd, err := NewCommand(context.Background(), "cat", withCommandService(mock))
+	validateError(t, nil, err)
+	stdin, err := cmd.StdinWriter()
+	validateError(t, nil, err)
+	io.WriteString(stdin, "data")
+	validateResult(t, "data", mock.stdin.String())
+
+	cmd, err = NewCommand(context.Background(), "cat", withCommandService(&CommandServiceMock{errStdinPipe: true}))
+	validateError(t, nil, err)
+	_, err = cmd.StdinWriter()
+	validateError(t, errors.New("errStdinPipe"), err)
+}
+
+func TestCommandStdinLines(t *testing.T) {
+	lines := make(chan string)
+	cmd, err := NewCommand(context.Background(), "cat", WithStreaming(), WithStdinLines(lines))
+	validateError(t, nil, err)
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+
+	for _, line := range []string{"one", "two"} {
+		select {
+		case lines <- line:
+		case <-time.After(time.Second):
+			t.Fatal("stdin line not consumed")
+		}
+		event := <-events
+		validateResult(t, []string{line}, event.Data().Stdout())
+	}
+	close(lines)
+	for range events {
+	}
+	validateResult(t, 0, (<-cmd.Wait()).ExitCode())
+}
+
+func TestCommandStdinLinesExit(t *testing.T) {
+	// the command exits without reading stdin, the open channel must not
+	// block completion
+	lines := make(chan string)
+	cmd, err := NewCommand(context.Background(), "true", WithStdinLines(lines))
+	validateError(t, nil, err)
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	<-events
+	validateResult(t, 0, (<-cmd.Wait()).ExitCode())
+}
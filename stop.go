@@ -0,0 +1,41 @@
+package command
+
+import (
+	"context"
+	"os/exec"
+)
+
+// ReasonStopped is the cancellation reason of executions stopped by Stop.
+const ReasonStopped = "stopped"
+
+// Stop terminates the process of the command without cancelling its
+// context, gracefully if WithGracefulStop or WithCancelFunc is set, and
+// blocks until the final state is available or ctx is done. The state
+// reports ReasonStopped as cancellation reason unless the command completed
+// before. The events of the command still need to be consumed. Stop returns
+// ErrNotStarted if Execute has not been called and ctx's error if ctx is
+// done first.
+func (c *Command) Stop(ctx context.Context) error {
+	if c.events == nil {
+		return ErrNotStarted
+	}
+	select {
+	case <-c.exited:
+		return nil
+	default:
+	}
+	c.mu.Lock()
+	if c.cancelReason == "" {
+		c.cancelReason = ReasonStopped
+	}
+	if cmd, ok := c.cmd.(*exec.Cmd); ok && cmd.Process != nil {
+		if cancel := c.cancel(cmd); cancel != nil {
+			cancel()
+		} else {
+			c.kill(cmd)
+		}
+	}
+	c.mu.Unlock()
+	_, err := c.WaitContext(ctx)
+	return err
+}
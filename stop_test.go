@@ -0,0 +1,77 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCommandStop(t *testing.T) {
+	// the trap interrupts wait, sleep is killed to not leak it
+	handled := "trap 'kill $!; exit 3' TERM; echo ready; sleep 10 >/dev/null 2>&1 & wait"
+	testCases := []struct {
+		name     string
+		args     []interface{}
+		script   string
+		execute  bool
+		timeout  time.Duration
+		exitCode int
+		reason   string
+		err      error
+	}{
+		{
+			name:     "graceful",
+			args:     []interface{}{WithGracefulStop(syscall.SIGTERM, 5*time.Second)},
+			script:   handled,
+			execute:  true,
+			exitCode: 3,
+			reason:   ReasonStopped,
+		},
+		{name: "kill", script: "echo ready; exec sleep 10", execute: true, exitCode: -1, reason: ReasonStopped},
+		{
+			name:     "timeout",
+			args:     []interface{}{WithGracefulStop(syscall.SIGTERM, 2*time.Millisecond)},
+			script:   "trap '' TERM; echo ready; exec sleep 10",
+			execute:  true,
+			timeout:  time.Millisecond,
+			exitCode: -1,
+			reason:   ReasonStopped,
+			err:      context.DeadlineExceeded,
+		},
+		{name: "completed", script: "echo ready", execute: true},
+		{name: "notStarted", script: "echo ready", err: ErrNotStarted},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cmd, err := NewCommand(ctx, "sh", append([]interface{}{"-c", tc.script, WithStreaming()}, tc.args...)...)
+			validateError(tt, nil, err)
+			if !tc.execute {
+				validateError(tt, tc.err, cmd.Stop(context.Background()))
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			<-events
+			go func() {
+				for range events {
+				}
+			}()
+			if tc.reason == "" {
+				<-cmd.Wait()
+			}
+			stopCtx, stopCancel := createTestContext(tc.timeout)
+			defer stopCancel()
+			validateError(tt, tc.err, cmd.Stop(stopCtx))
+			validateError(tt, nil, ctx.Err())
+			state := <-cmd.Wait()
+			validateResult(tt, tc.exitCode, state.ExitCode())
+			validateResult(tt, tc.reason, state.CancelReason())
+		})
+	}
+}
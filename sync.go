@@ -0,0 +1,66 @@
+package command
+
+import "context"
+
+// Run executes name with args, which may contain both arguments and
+// Options, and waits for it to finish. The output is discarded (see
+// WithDiscardOutput). The error is the error of the final State, or the
+// error of NewCommand or Execute in which case the State is nil.
+func Run(ctx context.Context, name string, args ...interface{}) (State, error) {
+	opts := append(append([]interface{}{}, args...), WithDiscardOutput())
+	cmd, err := NewCommand(ctx, name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cmd.Execute(); err != nil {
+		return nil, err
+	}
+	state := <-cmd.Wait()
+	return state, state.Error()
+}
+
+// Output is like Run but returns the stdout lines.
+func Output(ctx context.Context, name string, args ...interface{}) ([]string, State, error) {
+	result, err := run(ctx, Spec{Name: name, Args: args})
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Data.Stdout(), result.State, result.State.Error()
+}
+
+// CombinedOutput is like Run but returns the stdout and stderr lines. The
+// command is executed in streaming mode. Local processes write both streams
+// to a single pipe (see WithCombinedPipe), so the lines are in the order
+// they were written; for other backends they are in the order they were
+// read.
+func CombinedOutput(ctx context.Context, name string, args ...interface{}) ([]string, State, error) {
+	opts := append(append([]interface{}{}, args...), WithStreaming(), withLocalCombinedPipe())
+	cmd, err := NewCommand(ctx, name, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	events, err := cmd.Execute()
+	if err != nil {
+		return nil, nil, err
+	}
+	out := []string{}
+	for event := range events {
+		if event.Error() == nil {
+			out = append(out, event.Data().Out()...)
+		}
+	}
+	state := <-cmd.Wait()
+	return out, state, state.Error()
+}
+
+// withLocalCombinedPipe applies WithCombinedPipe unless a backend or service
+// was set by the preceding options.
+func withLocalCombinedPipe() Option {
+
+	return func(c *Command) error {
+		if c.cmd != nil {
+			return nil
+		}
+		return WithCombinedPipe()(c)
+	}
+}
@@ -0,0 +1,56 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []interface{}
+		stdout   []string
+		combined []string
+		exitCode int
+		err      error
+	}{
+		{name: "success", args: []interface{}{"-c", "echo 1; echo 2 >&2; echo 3"}, stdout: []string{"1", "3"}, combined: []string{"1", "2", "3"}},
+		{name: "failure", args: []interface{}{"-c", "echo 1; exit 3"}, stdout: []string{"1"}, combined: []string{"1"}, exitCode: 3, err: errors.New("exit status 3")},
+		{name: "invalidOption", args: []interface{}{WithSampleLines(0)}, exitCode: -1, err: errors.New("invalid sample rate: 0")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			exitCode := func(state State) int {
+				if state == nil {
+					return -1
+				}
+				return state.ExitCode()
+			}
+			state, err := Run(context.Background(), "sh", tc.args...)
+			validateError(tt, tc.err, err)
+			validateResult(tt, tc.exitCode, exitCode(state))
+
+			stdout, state, err := Output(context.Background(), "sh", tc.args...)
+			validateError(tt, tc.err, err)
+			validateResult(tt, tc.exitCode, exitCode(state))
+			validateResult(tt, tc.stdout, stdout)
+
+			combined, state, err := CombinedOutput(context.Background(), "sh", tc.args...)
+			validateError(tt, tc.err, err)
+			validateResult(tt, tc.exitCode, exitCode(state))
+			validateResult(tt, tc.combined, combined)
+		})
+	}
+
+	// CombinedOutput must not modify the arguments of the caller
+	args := make([]interface{}, 2, 3)
+	copy(args, []interface{}{"-c", "echo 1"})
+	backing := args[:3]
+	_, _, err := CombinedOutput(context.Background(), "sh", args...)
+	validateError(t, nil, err)
+	validateResult(t, nil, backing[2])
+}
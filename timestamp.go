@@ -0,0 +1,77 @@
+package command
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimestampFormat defines the format of line timestamps.
+type TimestampFormat int
+
+const (
+	// TimestampRFC3339 is the time the line was read in RFC 3339 format
+	// with nanoseconds, e.g. "2006-01-02T15:04:05.999999999Z07:00".
+	TimestampRFC3339 TimestampFormat = iota
+
+	// TimestampRelative is the time the line was read relative to the
+	// process start in seconds, e.g. "+1.234s".
+	TimestampRelative
+)
+
+func (f TimestampFormat) String() string {
+	switch f {
+	case TimestampRFC3339:
+		return "rfc3339"
+	case TimestampRelative:
+		return "relative"
+	}
+	return fmt.Sprintf("TimestampFormat(%d)", int(f))
+}
+
+// WithTimestamps prepends the time a line was read and a space to every
+// line of output, so the event data and the lines written to sinks carry
+// it. Like the prefix of WithLinePrefix, which follows the timestamp, it
+// is not added to chunks and continued segments of a line.
+func WithTimestamps(format TimestampFormat) Option {
+
+	return func(c *Command) error {
+		c.record("WithTimestamps", format)
+		stamp, err := c.timestamper(format)
+		if err != nil {
+			return err
+		}
+		c.timestamps = stamp
+		return nil
+	}
+}
+
+// WithSinkTimestamps is like WithTimestamps, but only the lines written to
+// sinks (see WithStdoutWriter) carry the timestamp. Lines of a batch (see
+// WithBatching) carry the time of the first one.
+func WithSinkTimestamps(format TimestampFormat) Option {
+
+	return func(c *Command) error {
+		c.record("WithSinkTimestamps", format)
+		stamp, err := c.timestamper(format)
+		if err != nil {
+			return err
+		}
+		c.writerSinks().stamp = stamp
+		return nil
+	}
+}
+
+// timestamper returns a function formatting the timestamp prefix of a line.
+func (c *Command) timestamper(format TimestampFormat) (func(time.Time) string, error) {
+	switch format {
+	case TimestampRFC3339:
+		return func(t time.Time) string {
+			return t.Format(time.RFC3339Nano) + " "
+		}, nil
+	case TimestampRelative:
+		return func(t time.Time) string {
+			return fmt.Sprintf("+%.3fs ", t.Sub(c.startTime).Seconds())
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid timestamp format: %d", int(format))
+}
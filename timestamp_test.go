@@ -0,0 +1,86 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestamps(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []interface{}
+		events *regexp.Regexp
+		sink   *regexp.Regexp
+		err    error
+	}{
+		{
+			name:   "relative",
+			args:   []interface{}{WithTimestamps(TimestampRelative), WithLinePrefix("web")},
+			events: regexp.MustCompile(`^\+0\.\d{3}s \[web\] \d$`),
+			sink:   regexp.MustCompile(`^\+0\.\d{3}s \[web\] \d$`),
+		},
+		{
+			name:   "rfc3339",
+			args:   []interface{}{WithTimestamps(TimestampRFC3339)},
+			events: regexp.MustCompile(`^\d{4}-\d\d-\d\dT\S+ \d$`),
+			sink:   regexp.MustCompile(`^\d{4}-\d\d-\d\dT\S+ \d$`),
+		},
+		{
+			name:   "sinksOnly",
+			args:   []interface{}{WithSinkTimestamps(TimestampRelative)},
+			events: regexp.MustCompile(`^\d$`),
+			sink:   regexp.MustCompile(`^\+0\.\d{3}s \d$`),
+		},
+		{name: "invalidFormat", args: []interface{}{WithTimestamps(TimestampFormat(7))}, err: errors.New("invalid timestamp format: 7")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			var sink bytes.Buffer
+			args := append([]interface{}{withCommandService(&CommandServiceMock{stdout: "1\n2\n"}), WithStreaming(), WithTee(&sink, nil)}, tc.args...)
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			lines := 0
+			for event := range events {
+				for _, line := range event.Data().Out() {
+					lines++
+					validateBool(tt, true, tc.events.MatchString(line))
+				}
+			}
+			validateResult(tt, 2, lines)
+			sinkLines := strings.Split(strings.TrimSuffix(sink.String(), "\n"), "\n")
+			validateResult(tt, 2, len(sinkLines))
+			for _, line := range sinkLines {
+				validateBool(tt, true, tc.sink.MatchString(line))
+			}
+		})
+	}
+}
+
+func TestTimestampRFC3339(t *testing.T) {
+	before := time.Now()
+	cmd, err := NewCommand(context.Background(), "echo", withCommandService(&CommandServiceMock{stdout: "1\n"}), WithTimestamps(TimestampRFC3339))
+	validateError(t, nil, err)
+	events, err := cmd.Execute()
+	validateError(t, nil, err)
+	event := <-events
+	for range events {
+	}
+	fields := strings.SplitN(event.Data().Stdout()[0], " ", 2)
+	stamp, err := time.Parse(time.RFC3339Nano, fields[0])
+	validateError(t, nil, err)
+	validateBool(t, false, stamp.Before(before.Truncate(time.Second)))
+	validateResult(t, "1", fields[1])
+}
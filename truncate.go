@@ -0,0 +1,74 @@
+package command
+
+import "fmt"
+
+// ErrOutputTruncated is the error of the event which signals that the output
+// exceeded the limit of WithMaxOutputLines or WithMaxOutputBytes.
+var ErrOutputTruncated = newCodedError("OUTPUT_TRUNCATED", "output truncated")
+
+// WithMaxOutputLines limits the output of the command to n lines of both
+// streams, to protect the host from runaway commands. Further lines are
+// dropped and counted by Stats.TruncatedLines and Stats.TruncatedBytes. In
+// streaming mode an event with ErrOutputTruncated is emitted at the first
+// dropped line; otherwise the error of the result event matches
+// ErrOutputTruncated, unless it carries another error.
+func WithMaxOutputLines(n int) Option {
+
+	return func(c *Command) error {
+		c.record("WithMaxOutputLines", n)
+		if n < 1 {
+			return fmt.Errorf("invalid max output lines: %d", n)
+		}
+		c.outputLimit().maxLines = int64(n)
+		return nil
+	}
+}
+
+// WithMaxOutputBytes limits the output of the command to n bytes of both
+// streams, excluding line terminators. See WithMaxOutputLines.
+func WithMaxOutputBytes(n int) Option {
+
+	return func(c *Command) error {
+		c.record("WithMaxOutputBytes", n)
+		if n < 1 {
+			return fmt.Errorf("invalid max output bytes: %d", n)
+		}
+		c.outputLimit().maxBytes = int64(n)
+		return nil
+	}
+}
+
+// outputCap tracks the output limits. It is only used by the goroutine
+// which forwards the events.
+type outputCap struct {
+	maxLines  int64
+	maxBytes  int64
+	lines     int64
+	bytes     int64
+	truncated bool
+}
+
+func (c *Command) outputLimit() *outputCap {
+	if c.outputCap == nil {
+		c.outputCap = &outputCap{}
+	}
+	return c.outputCap
+}
+
+// admit reports whether d fits into the limits. Once an event was dropped,
+// every following one is dropped as well. first is set for the first
+// dropped event.
+func (o *outputCap) admit(d Data) (ok, first bool) {
+	lines := int64(d.StdoutLines() + d.StderrLines())
+	bytes := int64(d.StdoutBytes() + d.StderrBytes())
+	if !o.truncated &&
+		(o.maxLines == 0 || o.lines+lines <= o.maxLines) &&
+		(o.maxBytes == 0 || o.bytes+bytes <= o.maxBytes) {
+		o.lines += lines
+		o.bytes += bytes
+		return true, false
+	}
+	first = !o.truncated
+	o.truncated = true
+	return false, first
+}
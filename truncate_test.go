@@ -0,0 +1,68 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMaxOutput(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      []interface{}
+		stream    bool
+		stdout    []string
+		lines     int64
+		bytes     int64
+		err       error
+		resultErr string
+	}{
+		{name: "lines", args: []interface{}{WithMaxOutputLines(3)}, stdout: []string{"1", "2", "3"}, lines: 7, bytes: 8, resultErr: "output truncated: 7 lines (8 bytes) dropped"},
+		{name: "bytes", args: []interface{}{WithMaxOutputBytes(5)}, stdout: []string{"1", "2", "3", "4", "5"}, lines: 5, bytes: 6, resultErr: "output truncated: 5 lines (6 bytes) dropped"},
+		{name: "both", args: []interface{}{WithMaxOutputLines(8), WithMaxOutputBytes(2)}, stdout: []string{"1", "2"}, lines: 8, bytes: 9, resultErr: "output truncated: 8 lines (9 bytes) dropped"},
+		{name: "fits", args: []interface{}{WithMaxOutputLines(10)}, stdout: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}, resultErr: "no error"},
+		{name: "stream", args: []interface{}{WithMaxOutputLines(2)}, stream: true, stdout: []string{"1", "2"}, lines: 8, bytes: 9},
+		{name: "invalidLines", args: []interface{}{WithMaxOutputLines(0)}, err: errors.New("invalid max output lines: 0")},
+		{name: "invalidBytes", args: []interface{}{WithMaxOutputBytes(-1)}, err: errors.New("invalid max output bytes: -1")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			args := append([]interface{}{withCommandService(&CommandServiceMock{stdout: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"})}, tc.args...)
+			if tc.stream {
+				args = append(args, WithStreaming())
+			}
+			cmd, err := NewCommand(context.Background(), "sh", args...)
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			stdout := []string{}
+			truncated := 0
+			for event := range events {
+				if tc.stream && event.Error() != nil {
+					validateError(tt, ErrOutputTruncated, event.Error())
+					truncated++
+					continue
+				}
+				stdout = append(stdout, event.Data().Stdout()...)
+				if tc.stream {
+					continue
+				}
+				validateError(tt, errors.New(tc.resultErr), event.Error())
+				validateBool(tt, tc.lines > 0, errors.Is(event.Error(), ErrOutputTruncated))
+			}
+			state := <-cmd.Wait()
+			validateResult(tt, tc.stdout, stdout)
+			validateResult(tt, tc.lines, state.Stats().TruncatedLines)
+			validateResult(tt, tc.bytes, state.Stats().TruncatedBytes)
+			if tc.stream {
+				validateResult(tt, 1, truncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidUTF8 is matched by the ReadError of a line with malformed UTF-8
+// if InvalidUTF8Error is set.
+var ErrInvalidUTF8 = newCodedError("INVALID_UTF8", "invalid UTF-8")
+
+// InvalidUTF8Policy defines how output lines with malformed UTF-8 are
+// handled.
+type InvalidUTF8Policy int
+
+const (
+	// InvalidUTF8Keep passes malformed bytes through, the default.
+	InvalidUTF8Keep InvalidUTF8Policy = iota
+
+	// InvalidUTF8Replace replaces each run of malformed bytes with U+FFFD.
+	InvalidUTF8Replace
+
+	// InvalidUTF8Drop removes malformed bytes.
+	InvalidUTF8Drop
+
+	// InvalidUTF8Error replaces the line with an event carrying a
+	// *ReadError which matches ErrInvalidUTF8. The error is reported by
+	// State.ReadErrors as well.
+	InvalidUTF8Error
+)
+
+func (p InvalidUTF8Policy) String() string {
+	switch p {
+	case InvalidUTF8Keep:
+		return "keep"
+	case InvalidUTF8Replace:
+		return "replace"
+	case InvalidUTF8Drop:
+		return "drop"
+	case InvalidUTF8Error:
+		return "error"
+	}
+	return fmt.Sprintf("InvalidUTF8Policy(%d)", int(p))
+}
+
+// WithInvalidUTF8 sets the policy for output lines with malformed UTF-8, so
+// that consumers which encode the output as JSON or log it don't break on
+// bad bytes. Chunks of WithChunkStreaming are not checked.
+func WithInvalidUTF8(policy InvalidUTF8Policy) Option {
+
+	return func(c *Command) error {
+		c.record("WithInvalidUTF8", policy)
+		if policy < InvalidUTF8Keep || policy > InvalidUTF8Error {
+			return fmt.Errorf("invalid UTF-8 policy: %d", int(policy))
+		}
+		c.utf8Policy = policy
+		return nil
+	}
+}
+
+// checkUTF8 applies the UTF-8 policy of the command to i.
+func (c *Command) checkUTF8(i streamData) streamData {
+	if c.utf8Policy == InvalidUTF8Keep || i.err != nil || i.chunk != nil || utf8.ValidString(i.data) {
+		return i
+	}
+	switch c.utf8Policy {
+	case InvalidUTF8Replace:
+		i.data = strings.ToValidUTF8(i.data, string(utf8.RuneError))
+	case InvalidUTF8Drop:
+		i.data = strings.ToValidUTF8(i.data, "")
+	case InvalidUTF8Error:
+		err := newReadError(i.isStderr, fmt.Errorf("%w: %q", ErrInvalidUTF8, i.data))
+		err.Kind = ErrInvalidUTF8
+		i = *newStreamData("", i.isStderr)
+		i.err = err
+	}
+	return i
+}
@@ -0,0 +1,47 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestInvalidUTF8(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy InvalidUTF8Policy
+		stdout []string
+		errs   int
+		err    error
+	}{
+		{name: "keep", policy: InvalidUTF8Keep, stdout: []string{"a\xffb", "ok"}},
+		{name: "replace", policy: InvalidUTF8Replace, stdout: []string{"a�b", "ok"}},
+		{name: "drop", policy: InvalidUTF8Drop, stdout: []string{"ab", "ok"}},
+		{name: "error", policy: InvalidUTF8Error, stdout: []string{"ok"}, errs: 1},
+		{name: "invalid", policy: InvalidUTF8Policy(9), err: errors.New("invalid UTF-8 policy: 9")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			mock := &CommandServiceMock{stdout: "a\xffb\nok\n"}
+			cmd, err := NewCommand(context.Background(), "sh", withCommandService(mock), WithInvalidUTF8(tc.policy))
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			events, err := cmd.Execute()
+			validateError(tt, nil, err)
+			event := <-events
+			validateResult(tt, tc.stdout, event.Data().Stdout())
+			state := <-cmd.Wait()
+			validateResult(tt, tc.errs, len(state.ReadErrors()))
+			for _, err := range state.ReadErrors() {
+				validateBool(tt, true, errors.Is(err, ErrInvalidUTF8))
+				validateResult(tt, "INVALID_UTF8", ErrorCode(err))
+				validateResult(tt, `read stdout: invalid UTF-8: "a\xffb"`, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SemVer is a semantic version parsed by Version.
+type SemVer struct {
+	Major int
+	Minor int
+	Patch int
+
+	// Pre is the pre-release identifier, e.g. "rc.1".
+	Pre string
+
+	// Build is the build metadata, e.g. "git.abc123".
+	Build string
+}
+
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is lower, equal or higher than o. Build
+// metadata is ignored and pre-release identifiers are compared as strings.
+func (v SemVer) Compare(o SemVer) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.Pre == o.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case o.Pre == "":
+		return -1
+	}
+	return strings.Compare(v.Pre, o.Pre)
+}
+
+func sign(d int) int {
+	if d < 0 {
+		return -1
+	}
+	return 1
+}
+
+// semVerPattern matches versions like "1.2", "v1.2.3" or "1.2.3-rc.1+abc".
+var semVerPattern = regexp.MustCompile(`\bv?(\d+)\.(\d+)(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?`)
+
+// ParseSemVer returns the first semantic version found in s. A missing patch
+// level is treated as 0.
+func ParseSemVer(s string) (SemVer, error) {
+	m := semVerPattern.FindStringSubmatch(s)
+	if m == nil {
+		return SemVer{}, fmt.Errorf("no version found in %q", s)
+	}
+	v := SemVer{Pre: m[4], Build: m[5]}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	return v, nil
+}
+
+// defaultVersionArgs are tried in order by Version if no arguments are given.
+var defaultVersionArgs = [][]string{{"--version"}, {"-V"}}
+
+// Version runs name with versionArgs and parses the first semantic version
+// from its output. Without versionArgs "--version" and "-V" are tried in
+// order. The exit code of the tool is ignored, as some tools report their
+// version with a non-zero status.
+func Version(ctx context.Context, name string, versionArgs ...string) (SemVer, error) {
+	candidates := defaultVersionArgs
+	if len(versionArgs) > 0 {
+		candidates = [][]string{versionArgs}
+	}
+	var err error
+	for _, args := range candidates {
+		spec := Spec{Name: name}
+		for _, arg := range args {
+			spec.Args = append(spec.Args, arg)
+		}
+		var result *Result
+		if result, err = run(ctx, spec); err != nil {
+			return SemVer{}, err
+		}
+		var v SemVer
+		if v, err = ParseSemVer(strings.Join(result.Data.Out(), "\n")); err == nil {
+			return v, nil
+		}
+	}
+	return SemVer{}, fmt.Errorf("%s: %w", name, err)
+}
@@ -0,0 +1,74 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseSemVer(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		expect SemVer
+		err    error
+	}{
+		{name: "plain", input: "1.2.3", expect: SemVer{Major: 1, Minor: 2, Patch: 3}},
+		{name: "git", input: "git version 2.39.2", expect: SemVer{Major: 2, Minor: 39, Patch: 2}},
+		{name: "prefix", input: "Client Version: v1.28.4", expect: SemVer{Major: 1, Minor: 28, Patch: 4}},
+		{name: "minor", input: "tool 3.1", expect: SemVer{Major: 3, Minor: 1}},
+		{name: "pre", input: "v1.0.0-rc.1+git.abc", expect: SemVer{Major: 1, Pre: "rc.1", Build: "git.abc"}},
+		{name: "none", input: "unknown", err: errors.New(`no version found in "unknown"`)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			got, err := ParseSemVer(tc.input)
+			validateError(tt, tc.err, err)
+			validateResult(tt, tc.expect, got)
+		})
+	}
+}
+
+func TestSemVerCompare(t *testing.T) {
+	testCases := []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "1.2.3", b: "1.2.3", expect: 0},
+		{a: "1.2.3", b: "1.10.0", expect: -1},
+		{a: "2.0.0", b: "1.99.99", expect: 1},
+		{a: "1.0.0-rc.1", b: "1.0.0", expect: -1},
+		{a: "1.0.0-rc.2", b: "1.0.0-rc.1", expect: 1},
+		{a: "1.0.0+a", b: "1.0.0+b", expect: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.a+"/"+tc.b, func(tt *testing.T) {
+			a, _ := ParseSemVer(tc.a)
+			b, _ := ParseSemVer(tc.b)
+			validateResult(tt, tc.expect, a.Compare(b))
+			validateResult(tt, tc.a, a.String())
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []string
+		expect SemVer
+		err    error
+	}{
+		{name: "args", args: []string{"-c", "echo tool v1.4.2 >&2; exit 1"}, expect: SemVer{Major: 1, Minor: 4, Patch: 2}},
+		{name: "noVersion", args: []string{"-c", "echo none"}, err: errors.New(`sh: no version found in "none"`)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			got, err := Version(context.Background(), "sh", tc.args...)
+			validateError(tt, tc.err, err)
+			validateResult(tt, tc.expect, got)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCommandWaitContext(t *testing.T) {
+	testCases := []struct {
+		name    string
+		script  string
+		execute bool
+		timeout time.Duration
+		exit    int
+		err     error
+	}{
+		{name: "completed", script: "exit 3", execute: true, exit: 3},
+		{name: "timeout", script: "exec sleep 10", execute: true, timeout: time.Millisecond, err: context.DeadlineExceeded},
+		{name: "notStarted", script: "exit 0", err: ErrNotStarted},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			cmd, err := NewCommand(context.Background(), "sh", "-c", tc.script)
+			validateError(tt, nil, err)
+			defer cmd.Close()
+			if tc.execute {
+				_, err := cmd.Execute()
+				validateError(tt, nil, err)
+			}
+			ctx, cancel := createTestContext(tc.timeout)
+			defer cancel()
+			var wg sync.WaitGroup
+			for i := 0; i < 3; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					state, err := cmd.WaitContext(ctx)
+					validateError(tt, tc.err, err)
+					if err == nil {
+						validateResult(tt, tc.exit, state.ExitCode())
+					}
+				}()
+			}
+			wg.Wait()
+			if tc.err == nil {
+				validateResult(tt, tc.exit, (<-cmd.Wait()).ExitCode())
+				validateResult(tt, tc.exit, (<-cmd.Wait()).ExitCode())
+			}
+		})
+	}
+}
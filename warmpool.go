@@ -0,0 +1,290 @@
+package command
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by a WarmPool after Close.
+var ErrPoolClosed = newCodedError("POOL_CLOSED", "pool closed")
+
+// ErrWorkerExited is returned by WarmPool.Do if the worker process exited
+// before answering the request.
+var ErrWorkerExited = newCodedError("WORKER_EXITED", "worker exited")
+
+// ReasonWorkerStopped is the cancellation reason of WarmPool workers which
+// were stopped to be replaced or because the pool was closed.
+const ReasonWorkerStopped = "worker stopped"
+
+// WarmPool keeps a fixed number of long running worker processes, e.g. an
+// interpreter serving requests on stdin, and dispatches work to them. This
+// avoids the startup cost of a process per invocation. Requests and
+// responses are framed as lines: a request is written as a single line to
+// the worker's stdin and the next line on its stdout is the response. Lines
+// read while no request is pending, e.g. banners, are discarded.
+// Workers which exit or fail are replaced on demand.
+//
+// Like database/sql connections, workers can also be checked out for
+// exclusive use with Checkout and returned with Return.
+type WarmPool struct {
+	// MaxLifetime is the maximum time a worker is reused. Older workers are
+	// replaced when they are checked out or returned. Zero means no limit.
+	MaxLifetime time.Duration
+
+	// HealthCheck, if set, is called whenever a worker is checked out.
+	// Workers failing the check are replaced.
+	HealthCheck func(ctx context.Context, h *Handle) error
+
+	spec   Spec
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// slots holds one entry per worker; nil entries are replaced by a new
+	// worker when taken.
+	slots     chan *warmWorker
+	size      int
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+// warmWorker is a single running worker process.
+type warmWorker struct {
+	cmd     *Command
+	stdin   io.WriteCloser
+	pending chan *warmRequest
+	done    chan struct{}
+	started time.Time
+}
+
+// warmRequest waits for the response to a request sent at since.
+type warmRequest struct {
+	since    time.Time
+	response chan string
+}
+
+// NewWarmPool starts size workers of spec. The workers are stopped when ctx
+// is done or the pool is closed.
+func NewWarmPool(ctx context.Context, spec Spec, size int) (*WarmPool, error) {
+	if size < 1 {
+		size = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	p := &WarmPool{spec: spec, ctx: ctx, cancel: cancel, slots: make(chan *warmWorker, size), size: size, closing: make(chan struct{})}
+	for i := 0; i < size; i++ {
+		w, err := p.spawn()
+		if err != nil {
+			for ; i < size; i++ {
+				p.slots <- nil
+			}
+			p.Close()
+			return nil, err
+		}
+		p.slots <- w
+	}
+	return p, nil
+}
+
+// spawn starts a new worker process.
+func (p *WarmPool) spawn() (*warmWorker, error) {
+	cmd, err := NewCommand(p.ctx, p.spec.Name, append([]interface{}{WithStreaming()}, p.spec.Args...)...)
+	if err != nil {
+		return nil, err
+	}
+	stdin, err := cmd.StdinWriter()
+	if err != nil {
+		return nil, err
+	}
+	events, err := cmd.Execute()
+	if err != nil {
+		return nil, err
+	}
+	w := &warmWorker{cmd: cmd, stdin: stdin, pending: make(chan *warmRequest, 1), done: make(chan struct{}), started: time.Now()}
+	go func() {
+		defer close(w.done)
+		// lines nobody waits for, e.g. banners, are discarded, so the
+		// events are always drained and the command can exit
+		var request *warmRequest
+		for event := range events {
+			for _, line := range event.Data().Stdout() {
+				if request == nil {
+					select {
+					case request = <-w.pending:
+					default:
+						continue
+					}
+				}
+				if event.Time().Before(request.since) {
+					continue
+				}
+				request.response <- line
+				request = nil
+			}
+		}
+		<-cmd.Wait()
+	}()
+	return w, nil
+}
+
+// stop kills the worker and waits until it exited.
+func (w *warmWorker) stop() {
+	w.cmd.CancelWithReason(ReasonWorkerStopped)
+	<-w.done
+}
+
+// exited reports whether the worker process already exited.
+func (w *warmWorker) exited() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// expired reports whether w exceeded the pool's MaxLifetime.
+func (p *WarmPool) expired(w *warmWorker) bool {
+	return p.MaxLifetime > 0 && time.Since(w.started) > p.MaxLifetime
+}
+
+// Handle is a worker checked out of a WarmPool for exclusive use. It must be
+// given back with WarmPool.Return.
+type Handle struct {
+	pool     *WarmPool
+	w        *warmWorker
+	broken   bool
+	returned bool
+}
+
+// Pid returns the process ID of the worker.
+func (h *Handle) Pid() int {
+	return h.w.cmd.Pid()
+}
+
+// Do sends request to the worker and returns its response, the first line
+// read from stdout after the request was sent. If the worker fails or ctx is
+// done before the response arrives the handle is marked as broken and the
+// worker is replaced on Return.
+func (h *Handle) Do(ctx context.Context, request string) (string, error) {
+	if h.broken {
+		return "", ErrWorkerExited
+	}
+	pending := &warmRequest{since: time.Now(), response: make(chan string, 1)}
+	h.w.pending <- pending
+	if _, err := io.WriteString(h.w.stdin, request+"\n"); err != nil {
+		h.broken = true
+		return "", err
+	}
+	select {
+	case response := <-pending.response:
+		return response, nil
+	case <-h.w.done:
+		h.broken = true
+		return "", ErrWorkerExited
+	case <-ctx.Done():
+		h.broken = true
+		return "", ctx.Err()
+	}
+}
+
+// Checkout waits for an idle worker and reserves it for the caller. Exited
+// and expired workers and workers failing the HealthCheck are replaced
+// first.
+func (p *WarmPool) Checkout(ctx context.Context) (*Handle, error) {
+	var w *warmWorker
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.ctx.Done():
+		return nil, ErrPoolClosed
+	case <-p.closing:
+		return nil, ErrPoolClosed
+	case w = <-p.slots:
+	}
+	select {
+	case <-p.closing:
+		// Close waits for this slot
+		p.slots <- w
+		return nil, ErrPoolClosed
+	default:
+	}
+	if w != nil && (w.exited() || p.expired(w)) {
+		w.stop()
+		w = nil
+	}
+	if w != nil {
+		h := &Handle{pool: p, w: w}
+		if p.healthy(ctx, h) {
+			return h, nil
+		}
+		w.stop()
+	}
+	// a fresh worker failing the health check is an error
+	w, err := p.spawn()
+	if err != nil {
+		p.slots <- nil
+		return nil, err
+	}
+	h := &Handle{pool: p, w: w}
+	if p.HealthCheck != nil {
+		if err := p.HealthCheck(ctx, h); err != nil {
+			w.stop()
+			p.slots <- nil
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// healthy runs the HealthCheck on h.
+func (p *WarmPool) healthy(ctx context.Context, h *Handle) bool {
+	return p.HealthCheck == nil || (p.HealthCheck(ctx, h) == nil && !h.broken)
+}
+
+// Return gives a checked out worker back to the pool. Broken and expired
+// workers are replaced. Returning a handle twice has no effect.
+func (p *WarmPool) Return(h *Handle) {
+	if h.returned {
+		return
+	}
+	h.returned = true
+	if h.broken || p.expired(h.w) {
+		h.w.stop()
+		p.slots <- nil
+		return
+	}
+	p.slots <- h.w
+}
+
+// Do sends request to an idle worker and returns its response. It waits for
+// a worker to become available. If the worker fails or ctx is done before
+// the response arrives the worker is replaced.
+func (p *WarmPool) Do(ctx context.Context, request string) (string, error) {
+	h, err := p.Checkout(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer p.Return(h)
+	return h.Do(ctx, request)
+}
+
+// Close stops all workers. Further checkouts fail with ErrPoolClosed, while
+// running requests complete and checked out workers must still be
+// returned. Close waits for them and can be called multiple times.
+func (p *WarmPool) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+		workers := make([]*warmWorker, 0, p.size)
+		for i := 0; i < p.size; i++ {
+			workers = append(workers, <-p.slots)
+		}
+		p.cancel()
+		for _, w := range workers {
+			if w != nil {
+				w.stop()
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,230 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// echoWorker answers every request line with "got <line>" and exits on
+// "exit".
+// "flood" is answered by additional lines nobody asked for.
+var echoWorker = Spec{Name: "sh", Args: []interface{}{"-c", `while read l; do [ "$l" = exit ] && exit 1; [ "$l" = hang ] && exec sleep 10; echo "got $l $$"; [ "$l" = flood ] && seq 100; done`}}
+
+func TestWarmPoolDo(t *testing.T) {
+	pool, err := NewWarmPool(context.Background(), echoWorker, 2)
+	validateError(t, nil, err)
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	pids := sync.Map{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := pool.Do(context.Background(), fmt.Sprint(i))
+			validateError(t, nil, err)
+			var n, pid int
+			fmt.Sscanf(response, "got %d %d", &n, &pid)
+			validateResult(t, i, n)
+			pids.Store(pid, true)
+		}(i)
+	}
+	wg.Wait()
+	count := 0
+	pids.Range(func(interface{}, interface{}) bool { count++; return true })
+	validateBool(t, true, count <= 2)
+}
+
+func TestWarmPoolReplace(t *testing.T) {
+	testCases := []struct {
+		name    string
+		request string
+		timeout time.Duration
+		err     error
+	}{
+		{name: "exit", request: "exit", err: ErrWorkerExited},
+		{name: "timeout", request: "hang", timeout: time.Millisecond, err: context.DeadlineExceeded},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			pool, err := NewWarmPool(context.Background(), echoWorker, 1)
+			validateError(tt, nil, err)
+			defer pool.Close()
+
+			ctx, cancel := createTestContext(tc.timeout)
+			defer cancel()
+			_, err = pool.Do(ctx, tc.request)
+			validateError(tt, tc.err, err)
+
+			response, err := pool.Do(context.Background(), "1")
+			validateError(tt, nil, err)
+			validateResult(tt, "got 1", response[:5])
+		})
+	}
+}
+
+func TestWarmPoolUnsolicited(t *testing.T) {
+	pool, err := NewWarmPool(context.Background(), echoWorker, 1)
+	validateError(t, nil, err)
+	defer pool.Close()
+
+	h, err := pool.Checkout(context.Background())
+	validateError(t, nil, err)
+	response, err := h.Do(context.Background(), "flood")
+	validateError(t, nil, err)
+	validateResult(t, "got flood", response[:9])
+
+	// the unsolicited lines must not block replacing the worker
+	for h.w.cmd.stats.snapshot().StdoutLines < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	pool.MaxLifetime = time.Nanosecond
+	pool.Return(h)
+	response, err = pool.Do(context.Background(), "1")
+	validateError(t, nil, err)
+	validateResult(t, "got 1", response[:5])
+}
+
+func TestWarmPoolClose(t *testing.T) {
+	pool, err := NewWarmPool(context.Background(), echoWorker, 2)
+	validateError(t, nil, err)
+	validateError(t, nil, pool.Close())
+	validateError(t, nil, pool.Close())
+	_, err = pool.Do(context.Background(), "1")
+	validateError(t, ErrPoolClosed, err)
+
+	_, err = NewWarmPool(context.Background(), Spec{Name: ""}, 2)
+	validateError(t, fmt.Errorf("name cannot be empty"), err)
+}
+
+func TestWarmPoolCloseWaits(t *testing.T) {
+	pool, err := NewWarmPool(context.Background(), echoWorker, 1)
+	validateError(t, nil, err)
+	h, err := pool.Checkout(context.Background())
+	validateError(t, nil, err)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		pool.Close()
+	}()
+	<-pool.closing
+	_, err = pool.Checkout(context.Background())
+	validateError(t, ErrPoolClosed, err)
+
+	// the running request is not canceled
+	response, err := h.Do(context.Background(), "1")
+	validateError(t, nil, err)
+	validateResult(t, "got 1", response[:5])
+	select {
+	case <-closed:
+		t.Fatal("Close returned before the worker was returned")
+	default:
+	}
+	pool.Return(h)
+	<-closed
+}
+
+func TestWarmPoolCheckout(t *testing.T) {
+	pool, err := NewWarmPool(context.Background(), echoWorker, 1)
+	validateError(t, nil, err)
+	defer pool.Close()
+
+	h, err := pool.Checkout(context.Background())
+	validateError(t, nil, err)
+	pid := h.Pid()
+
+	ctx, cancel := createTestContext(time.Millisecond)
+	defer cancel()
+	_, err = pool.Checkout(ctx)
+	validateError(t, context.DeadlineExceeded, err)
+
+	response, err := h.Do(context.Background(), "1")
+	validateError(t, nil, err)
+	validateResult(t, fmt.Sprintf("got 1 %d", pid), response)
+	pool.Return(h)
+	pool.Return(h)
+
+	h, err = pool.Checkout(context.Background())
+	validateError(t, nil, err)
+	validateResult(t, pid, h.Pid())
+	pool.Return(h)
+}
+
+func TestWarmPoolCheckoutExited(t *testing.T) {
+	pool, err := NewWarmPool(context.Background(), echoWorker, 1)
+	validateError(t, nil, err)
+	defer pool.Close()
+
+	h, err := pool.Checkout(context.Background())
+	validateError(t, nil, err)
+	pid := h.Pid()
+	h.w.cmd.CancelWithReason(ReasonWorkerStopped)
+	<-h.w.done
+	pool.Return(h)
+
+	h, err = pool.Checkout(context.Background())
+	validateError(t, nil, err)
+	defer pool.Return(h)
+	validateBool(t, true, pid != h.Pid())
+	response, err := h.Do(context.Background(), "1")
+	validateError(t, nil, err)
+	validateResult(t, "got 1", response[:5])
+}
+
+func TestWarmPoolRecycle(t *testing.T) {
+	testCases := []struct {
+		name        string
+		maxLifetime time.Duration
+		healthCheck func(ctx context.Context, h *Handle) error
+		recycled    bool
+		err         error
+	}{
+		{name: "reuse", maxLifetime: time.Hour, recycled: false},
+		{name: "maxLifetime", maxLifetime: time.Nanosecond, recycled: true},
+		{
+			name: "healthy",
+			healthCheck: func(ctx context.Context, h *Handle) error {
+				_, err := h.Do(ctx, "ping")
+				return err
+			},
+			recycled: false,
+		},
+		{
+			name: "unhealthy",
+			healthCheck: func(ctx context.Context, h *Handle) error {
+				_, err := h.Do(ctx, "exit")
+				return err
+			},
+			err: ErrWorkerExited,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			pool, err := NewWarmPool(context.Background(), echoWorker, 1)
+			validateError(tt, nil, err)
+			defer pool.Close()
+
+			h, err := pool.Checkout(context.Background())
+			validateError(tt, nil, err)
+			pid := h.Pid()
+			pool.Return(h)
+
+			pool.MaxLifetime = tc.maxLifetime
+			pool.HealthCheck = tc.healthCheck
+			h, err = pool.Checkout(context.Background())
+			validateError(tt, tc.err, err)
+			if err != nil {
+				return
+			}
+			validateBool(tt, tc.recycled, pid != h.Pid())
+			pool.Return(h)
+		})
+	}
+}
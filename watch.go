@@ -0,0 +1,155 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// WatchRunLabel is the label key carrying the run number of events emitted
+// by a Watcher.
+const WatchRunLabel = "watch.run"
+
+// Watcher re-executes a command whenever one of the watched paths changes.
+// Changes are detected by polling the modification time and size of the
+// watched files; directories are watched recursively.
+type Watcher struct {
+	spec  Spec
+	paths []string
+
+	// Debounce is the quiet period after a change before the command is
+	// restarted.
+	Debounce time.Duration
+
+	// PollInterval is the interval in which the paths are checked.
+	PollInterval time.Duration
+}
+
+// NewWatcher returns a Watcher which executes spec whenever one of paths
+// changes.
+func NewWatcher(spec Spec, paths ...string) *Watcher {
+	return &Watcher{
+		spec:         spec,
+		paths:        paths,
+		Debounce:     100 * time.Millisecond,
+		PollInterval: 250 * time.Millisecond,
+	}
+}
+
+// Watch executes the command once and again after every change, cancelling
+// a still running execution first. The streamed events of all runs are sent
+// to the returned channel and labeled with their run number (WatchRunLabel).
+// The channel is closed when ctx is done and the last run has finished.
+func (w *Watcher) Watch(ctx context.Context) <-chan Event {
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+		var cancelRun context.CancelFunc
+		var runDone chan struct{}
+		runs := 0
+
+		start := func() {
+			runs++
+			runCtx, cancel := context.WithCancel(ctx)
+			cancelRun = cancel
+			runDone = make(chan struct{})
+			go w.run(runCtx, runs, out, runDone)
+		}
+		stop := func() {
+			if cancelRun != nil {
+				cancelRun()
+				<-runDone
+			}
+		}
+
+		poll := time.NewTicker(w.PollInterval)
+		defer poll.Stop()
+		snap := snapshotPaths(w.paths)
+		var debounce <-chan time.Time
+		start()
+		for {
+			select {
+			case <-ctx.Done():
+				stop()
+				return
+			case <-poll.C:
+				if next := snapshotPaths(w.paths); !sameSnapshot(snap, next) {
+					snap = next
+					debounce = time.After(w.Debounce)
+				}
+			case <-debounce:
+				debounce = nil
+				stop()
+				start()
+			}
+		}
+	}()
+	return out
+}
+
+// run executes a single run and forwards its events to out.
+func (w *Watcher) run(ctx context.Context, n int, out chan<- Event, done chan<- struct{}) {
+	defer close(done)
+	labels := map[string]string{WatchRunLabel: strconv.Itoa(n)}
+	args := append([]interface{}{WithStreaming(), WithContextLabels(func(context.Context) map[string]string { return labels })}, w.spec.Args...)
+
+	forward := func(evt Event) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- evt:
+			return true
+		}
+	}
+	cmd, err := NewCommand(ctx, w.spec.Name, args...)
+	var events <-chan Event
+	if err == nil {
+		events, err = cmd.Execute()
+	}
+	if err != nil {
+		evt := newCommandEvent(newStreamData("", false), err)
+		evt.labels = labels
+		evt.name = w.spec.Name
+		forward(evt)
+		return
+	}
+	for evt := range events {
+		forward(evt)
+	}
+	<-cmd.Wait()
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func snapshotPaths(paths []string) map[string]fileStamp {
+	snap := make(map[string]fileStamp)
+	for _, root := range paths {
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			snap[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+	}
+	return snap
+}
+
+func sameSnapshot(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		if other, ok := b[path]; !ok || !other.modTime.Equal(stamp.modTime) || other.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+// +build !integration
+// +build unit
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSameSnapshot(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name   string
+		a, b   map[string]fileStamp
+		expect bool
+	}{
+		{name: "equal", a: map[string]fileStamp{"a": {now, 1}}, b: map[string]fileStamp{"a": {now, 1}}, expect: true},
+		{name: "modified", a: map[string]fileStamp{"a": {now, 1}}, b: map[string]fileStamp{"a": {now.Add(time.Second), 1}}},
+		{name: "resized", a: map[string]fileStamp{"a": {now, 1}}, b: map[string]fileStamp{"a": {now, 2}}},
+		{name: "added", a: map[string]fileStamp{"a": {now, 1}}, b: map[string]fileStamp{"a": {now, 1}, "b": {now, 1}}},
+		{name: "renamed", a: map[string]fileStamp{"a": {now, 1}}, b: map[string]fileStamp{"b": {now, 1}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			validateBool(tt, tc.expect, sameSnapshot(tc.a, tc.b))
+		})
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	validateError(t, nil, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "trigger")
+	validateError(t, nil, ioutil.WriteFile(file, []byte("a"), 0644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	w := NewWatcher(Spec{Name: "echo", Args: []interface{}{withCommandService(&CommandServiceMock{stdout: "run\n"})}}, dir)
+	w.Debounce = time.Millisecond
+	w.PollInterval = time.Millisecond
+
+	got := map[string][]string{}
+	for event := range w.Watch(ctx) {
+		run := event.Labels()[WatchRunLabel]
+		got[run] = append(got[run], event.Data().Stdout()...)
+		switch run {
+		case "1":
+			validateError(t, nil, ioutil.WriteFile(file, []byte("bb"), 0644))
+		case "2":
+			cancel()
+		}
+	}
+	validateResult(t, map[string][]string{"1": {"run"}, "2": {"run"}}, got)
+}